@@ -118,6 +118,86 @@ func (x *HealthResponse) GetBuild() string {
 	return ""
 }
 
+type ReadyRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ReadyRequest) Reset() {
+	*x = ReadyRequest{}
+	mi := &file_health_v1_health_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReadyRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReadyRequest) ProtoMessage() {}
+
+func (x *ReadyRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_health_v1_health_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReadyRequest.ProtoReflect.Descriptor instead.
+func (*ReadyRequest) Descriptor() ([]byte, []int) {
+	return file_health_v1_health_proto_rawDescGZIP(), []int{2}
+}
+
+type ReadyResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Status        string                 `protobuf:"bytes,1,opt,name=status,proto3" json:"status,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ReadyResponse) Reset() {
+	*x = ReadyResponse{}
+	mi := &file_health_v1_health_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReadyResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReadyResponse) ProtoMessage() {}
+
+func (x *ReadyResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_health_v1_health_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReadyResponse.ProtoReflect.Descriptor instead.
+func (*ReadyResponse) Descriptor() ([]byte, []int) {
+	return file_health_v1_health_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *ReadyResponse) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
 var File_health_v1_health_proto protoreflect.FileDescriptor
 
 const file_health_v1_health_proto_rawDesc = "" +
@@ -127,9 +207,13 @@ const file_health_v1_health_proto_rawDesc = "" +
 	"\x0eHealthResponse\x12\x16\n" +
 	"\x06status\x18\x01 \x01(\tR\x06status\x12\x18\n" +
 	"\aversion\x18\x02 \x01(\tR\aversion\x12\x14\n" +
-	"\x05build\x18\x03 \x01(\tR\x05build2_\n" +
+	"\x05build\x18\x03 \x01(\tR\x05build\"\x0e\n" +
+	"\fReadyRequest\"'\n" +
+	"\rReadyResponse\x12\x16\n" +
+	"\x06status\x18\x01 \x01(\tR\x06status2\xab\x01\n" +
 	"\rHealthService\x12N\n" +
-	"\x06Health\x12\x18.health.v1.HealthRequest\x1a\x19.health.v1.HealthResponse\"\x0f\x82\xd3\xe4\x93\x02\t\x12\a/healthB5Z3github.com/desulaidovich/app/api/health/v1;healthv1b\x06proto3"
+	"\x06Health\x12\x18.health.v1.HealthRequest\x1a\x19.health.v1.HealthResponse\"\x0f\x82\xd3\xe4\x93\x02\t\x12\a/health\x12J\n" +
+	"\x05Ready\x12\x17.health.v1.ReadyRequest\x1a\x18.health.v1.ReadyResponse\"\x0e\x82\xd3\xe4\x93\x02\b\x12\x06/readyB5Z3github.com/desulaidovich/app/api/health/v1;healthv1b\x06proto3"
 
 var (
 	file_health_v1_health_proto_rawDescOnce sync.Once
@@ -143,16 +227,20 @@ func file_health_v1_health_proto_rawDescGZIP() []byte {
 	return file_health_v1_health_proto_rawDescData
 }
 
-var file_health_v1_health_proto_msgTypes = make([]protoimpl.MessageInfo, 2)
+var file_health_v1_health_proto_msgTypes = make([]protoimpl.MessageInfo, 4)
 var file_health_v1_health_proto_goTypes = []any{
 	(*HealthRequest)(nil),  // 0: health.v1.HealthRequest
 	(*HealthResponse)(nil), // 1: health.v1.HealthResponse
+	(*ReadyRequest)(nil),   // 2: health.v1.ReadyRequest
+	(*ReadyResponse)(nil),  // 3: health.v1.ReadyResponse
 }
 var file_health_v1_health_proto_depIdxs = []int32{
 	0, // 0: health.v1.HealthService.Health:input_type -> health.v1.HealthRequest
-	1, // 1: health.v1.HealthService.Health:output_type -> health.v1.HealthResponse
-	1, // [1:2] is the sub-list for method output_type
-	0, // [0:1] is the sub-list for method input_type
+	2, // 1: health.v1.HealthService.Ready:input_type -> health.v1.ReadyRequest
+	1, // 2: health.v1.HealthService.Health:output_type -> health.v1.HealthResponse
+	3, // 3: health.v1.HealthService.Ready:output_type -> health.v1.ReadyResponse
+	2, // [2:4] is the sub-list for method output_type
+	0, // [0:2] is the sub-list for method input_type
 	0, // [0:0] is the sub-list for extension type_name
 	0, // [0:0] is the sub-list for extension extendee
 	0, // [0:0] is the sub-list for field type_name
@@ -169,7 +257,7 @@ func file_health_v1_health_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_health_v1_health_proto_rawDesc), len(file_health_v1_health_proto_rawDesc)),
 			NumEnums:      0,
-			NumMessages:   2,
+			NumMessages:   4,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
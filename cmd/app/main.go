@@ -2,12 +2,19 @@ package main
 
 import (
 	"context"
+	"fmt"
+	"os"
 	"syscall"
 	"time"
 
 	"github.com/desulaidovich/app/config"
 	"github.com/desulaidovich/app/internal/app"
+	"github.com/desulaidovich/app/internal/handler"
+	"github.com/desulaidovich/app/internal/migrator"
+	"github.com/desulaidovich/app/internal/outbox"
 	"github.com/desulaidovich/app/internal/postgres"
+	"github.com/desulaidovich/app/internal/queue"
+	"github.com/desulaidovich/app/internal/scheduler"
 	"github.com/desulaidovich/app/pkg/env"
 	"github.com/desulaidovich/app/pkg/log"
 	"github.com/desulaidovich/app/pkg/runner"
@@ -20,6 +27,10 @@ var (
 )
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "probe" {
+		os.Exit(runProbe(os.Args[1:]))
+	}
+
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
@@ -46,24 +57,95 @@ func main() {
 		postgres.WithConnectTimeout(cfg.Database.Pool.ConnectTimeout),
 		postgres.WithHealthCheckPeriod(2*time.Minute),
 		postgres.WithSSLMode(cfg.Database.SSLMode),
+		postgres.WithLogger(logger),
+		postgres.WithSlowQueryThreshold(cfg.Database.SlowQueryThreshold),
+		postgres.WithQueryLogging(cfg.App.Debug),
+		postgres.WithReplicas(cfg.ReplicaDSNs()...),
+		postgres.WithReplicaStrategy(cfg.Database.Replicas.Strategy),
+		postgres.WithMaxReplicationLag(cfg.Database.Replicas.MaxLag),
+		postgres.WithReplicaCheckPeriod(cfg.Database.Replicas.CheckPeriod),
 	)
 	if err != nil {
 		panic("failed to connect to database: " + err.Error())
 	}
 	defer db.Close()
 
+	if err := migrate(ctx, &cfg, db, logger); err != nil {
+		panic("failed to migrate database: " + err.Error())
+	}
+
+	txManager, err := postgres.NewTxManager(db, postgres.WithTxLogger(logger))
+	if err != nil {
+		panic("failed to create transaction manager: " + err.Error())
+	}
+
+	listener, err := postgres.NewListener(db, postgres.WithListenerLogger(logger))
+	if err != nil {
+		panic("failed to create listener: " + err.Error())
+	}
+
+	jobs, err := queue.New(db, txManager,
+		queue.WithLogger(logger),
+		queue.WithListener(listener),
+		queue.WithQueue(queue.DefaultQueue, cfg.Queue.Concurrency),
+		queue.WithPollInterval(cfg.Queue.PollInterval),
+		queue.WithJobTimeout(cfg.Queue.JobTimeout),
+	)
+	if err != nil {
+		panic("failed to create job queue: " + err.Error())
+	}
+
+	publisher, err := newPublisher(&cfg, logger)
+	if err != nil {
+		panic("failed to create outbox publisher: " + err.Error())
+	}
+
+	relay, err := outbox.NewRelay(db, publisher,
+		outbox.WithLogger(logger),
+		outbox.WithListener(listener),
+		outbox.WithPollInterval(cfg.Outbox.PollInterval),
+		outbox.WithBatchSize(cfg.Outbox.BatchSize),
+	)
+	if err != nil {
+		panic("failed to create outbox relay: " + err.Error())
+	}
+
+	elector, err := postgres.NewElector(db, cfg.App.Name,
+		postgres.WithElectorLogger(logger),
+		postgres.WithElectorRetryPeriod(cfg.Leader.RetryPeriod),
+		postgres.WithElectorCheckPeriod(cfg.Leader.CheckPeriod),
+	)
+	if err != nil {
+		panic("failed to create leader elector: " + err.Error())
+	}
+
+	sched, err := newScheduler(&cfg, db, elector, jobs, relay, logger)
+	if err != nil {
+		panic("failed to create scheduler: " + err.Error())
+	}
+
 	application, err := app.New(
 		app.WithAppName(cfg.App.Name),
 		app.WithVersion(version, build),
 		app.WithConfig(&cfg),
 		app.WithLogger(logger),
-		app.WithPostgres(db),
+		app.WithServices(
+			handler.NewHealthHandler(version, build, db),
+			handler.NewJobsHandler(jobs, cfg.App.AdminToken.Value()),
+			handler.NewSchedulerHandler(sched, cfg.App.AdminToken.Value()),
+			// tool generate service добавляет новые сервисы перед этой строкой.
+		),
 	)
 	if err != nil {
-		panic("failed to run migrations: " + err.Error())
+		panic("failed to create application: " + err.Error())
 	}
 
 	r, err := runner.New(
+		runner.WithHandler(listener),
+		runner.WithHandler(jobs),
+		runner.WithHandler(relay),
+		runner.WithHandler(elector),
+		runner.WithHandler(sched),
 		runner.WithHandler(application),
 		runner.WithSignals(syscall.SIGINT, syscall.SIGTERM),
 		runner.WithStopTimeout(5*time.Second),
@@ -76,3 +158,98 @@ func main() {
 		panic("runner exited with error: " + err.Error())
 	}
 }
+
+// migrate применяет встроенные миграции, если включён APP_MIGRATE_ON_START,
+// и сверяет версию схемы с последней встроенной миграцией.
+func migrate(ctx context.Context, cfg *config.Config, db *postgres.Pool, logger log.Logger) error {
+	m, err := migrator.NewFromPool(db.Pool,
+		migrator.WithLogger(logger),
+		migrator.WithLockTimeout(cfg.App.MigrateLockTimeout),
+	)
+	if err != nil {
+		return err
+	}
+	defer m.Close()
+
+	if cfg.App.MigrateOnStart {
+		results, err := m.Up(ctx)
+		for _, r := range results {
+			logger.With(map[string]any{
+				"version":  r.Version,
+				"path":     r.Path,
+				"duration": r.Duration.String(),
+			}).Info("Migration applied")
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	current, err := m.Version(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get schema version: %w", err)
+	}
+	latest, err := m.Latest()
+	if err != nil {
+		return err
+	}
+
+	if current >= latest {
+		logger.With(map[string]any{"version": current}).Info("Database schema is up to date")
+		return nil
+	}
+	if cfg.App.SchemaCheck == "fail" {
+		return fmt.Errorf("database schema version %d is behind latest migration %d", current, latest)
+	}
+	logger.With(map[string]any{
+		"version": current,
+		"latest":  latest,
+	}).Warn("Database schema is behind latest migration")
+	return nil
+}
+
+func newPublisher(cfg *config.Config, logger log.Logger) (outbox.Publisher, error) {
+	switch cfg.Outbox.Publisher {
+	case "log":
+		return outbox.NewLogPublisher(logger)
+	case "webhook":
+		return outbox.NewWebhookPublisher(cfg.Outbox.WebhookURL, nil)
+	case "file":
+		return outbox.NewFilePublisher(cfg.Outbox.File)
+	default:
+		return nil, fmt.Errorf("unsupported outbox publisher: %q (valid: log, webhook, file)", cfg.Outbox.Publisher)
+	}
+}
+
+func newScheduler(
+	cfg *config.Config,
+	db *postgres.Pool,
+	elector *postgres.Elector,
+	jobs *queue.Queue,
+	relay *outbox.Relay,
+	logger log.Logger,
+) (*scheduler.Scheduler, error) {
+	s, err := scheduler.New(db,
+		scheduler.WithLogger(logger),
+		scheduler.WithElector(elector),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.Register("purge-jobs", "@hourly", func(ctx context.Context) error {
+		_, err := jobs.Purge(ctx, cfg.Scheduler.JobRetention)
+		return err
+	}, scheduler.LeaderOnly()); err != nil {
+		return nil, err
+	}
+
+	if err := s.Register("purge-outbox", "@hourly", func(ctx context.Context) error {
+		_, err := relay.Purge(ctx, cfg.Scheduler.OutboxRetention)
+		return err
+	}, scheduler.LeaderOnly()); err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
@@ -0,0 +1,142 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+
+	healthv1 "github.com/desulaidovich/app/api/health/v1"
+	"github.com/desulaidovich/app/config"
+	"github.com/desulaidovich/app/pkg/cli"
+	"github.com/desulaidovich/app/pkg/env"
+)
+
+const (
+	probeLive  = "live"
+	probeReady = "ready"
+)
+
+// runProbe выполняет "app probe ..." и возвращает код завершения для HEALTHCHECK
+// и exec-проб: 0 — здоров, 1 — нет. Код 2 Docker резервирует, поэтому ошибки
+// вызова тоже превращаются в 1.
+func runProbe(args []string) int {
+	root := &cli.Command{Name: "app"}
+	root.Add(probeCommand())
+
+	if code := cli.Execute(context.Background(), root, args); code != cli.ExitOK {
+		return cli.ExitFailure
+	}
+	return cli.ExitOK
+}
+
+func probeCommand() *cli.Command {
+	cmd := &cli.Command{
+		Name:  "probe",
+		Short: "Check a running instance for container health checks",
+		Long: `Check a running instance for container health checks.
+
+Calls the liveness (/health) or readiness (/ready) endpoint of the instance
+described by the same configuration (-env-file and environment) over HTTP or gRPC.
+Only HTTP_PORT and GRPC_PORT are read, so other missing or invalid variables do
+not fail the check. Exits with 0 if the instance is healthy and 1 otherwise.
+
+  HEALTHCHECK CMD ["/app", "probe", "-check", "ready"]`,
+	}
+	check := cmd.Flags().String("check", probeLive, "endpoint to call (live, ready)")
+	transport := cmd.Flags().String("transport", "http", "transport (http, grpc)")
+	addr := cmd.Flags().String("addr", "", "host:port to call, defaults to localhost and HTTP_PORT or GRPC_PORT")
+	timeout := cmd.Flags().Duration("timeout", 3*time.Second, "timeout of the whole check")
+	envFile := cmd.Flags().String("env-file", ".env", "dotenv file to load ports from")
+
+	cmd.Run = func(ctx context.Context, args []string) error {
+		if len(args) != 0 {
+			return cli.Usagef("unexpected arguments: %v", args)
+		}
+		if *check != probeLive && *check != probeReady {
+			return cli.Usagef("unsupported check: %q (valid: live, ready)", *check)
+		}
+
+		var ports config.Ports
+		if err := env.Load(&ports, *envFile); err != nil {
+			return fmt.Errorf("failed to load config: %w", err)
+		}
+
+		ctx, cancel := context.WithTimeout(ctx, *timeout)
+		defer cancel()
+
+		var err error
+		switch *transport {
+		case "http":
+			err = probeHTTP(ctx, probeAddr(*addr, ports.HTTP.Port), *check)
+		case "grpc":
+			err = probeGRPC(ctx, probeAddr(*addr, ports.GRPC.Port), *check)
+		default:
+			return cli.Usagef("unsupported transport: %q (valid: http, grpc)", *transport)
+		}
+		if err != nil {
+			return err
+		}
+
+		fmt.Fprintln(os.Stdout, "ok")
+		return nil
+	}
+	return cmd
+}
+
+func probeAddr(addr string, port int) string {
+	if addr != "" {
+		return addr
+	}
+	return net.JoinHostPort("localhost", strconv.Itoa(port))
+}
+
+func probeHTTP(ctx context.Context, addr, check string) error {
+	path := "/health"
+	if check == probeReady {
+		path = "/ready"
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, "http://"+addr+path, nil)
+	if err != nil {
+		return fmt.Errorf("failed to build request: %w", err)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("%s check failed: %w", check, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("%s check failed: %s: %s", check, resp.Status, body)
+	}
+	return nil
+}
+
+func probeGRPC(ctx context.Context, addr, check string) error {
+	conn, err := grpc.NewClient(addr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		return fmt.Errorf("failed to create grpc client: %w", err)
+	}
+	defer conn.Close()
+
+	client := healthv1.NewHealthServiceClient(conn)
+	if check == probeReady {
+		_, err = client.Ready(ctx, &healthv1.ReadyRequest{})
+	} else {
+		_, err = client.Health(ctx, &healthv1.HealthRequest{})
+	}
+	if err != nil {
+		return fmt.Errorf("%s check failed: %w", check, err)
+	}
+	return nil
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+func TestProbeAddr(t *testing.T) {
+	tests := []struct {
+		addr string
+		port int
+		want string
+	}{
+		{port: 8080, want: "localhost:8080"},
+		{addr: "app:9000", port: 8080, want: "app:9000"},
+		{addr: "[::1]:9000", port: 9090, want: "[::1]:9000"},
+	}
+
+	for _, tt := range tests {
+		if got := probeAddr(tt.addr, tt.port); got != tt.want {
+			t.Errorf("probeAddr(%q, %d) = %q, want %q", tt.addr, tt.port, got, tt.want)
+		}
+	}
+}
+
+func TestRunProbe(t *testing.T) {
+	var ready atomic.Bool
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/health", r.URL.Path == "/ready" && ready.Load():
+			w.WriteHeader(http.StatusOK)
+		default:
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	t.Cleanup(srv.Close)
+
+	addr := strings.TrimPrefix(srv.URL, "http://")
+	_, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dir := t.TempDir()
+	envFile := func(name string, lines ...string) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(strings.Join(lines, "\n")), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+	missing := filepath.Join(dir, "missing.env")
+	// Остальные переменные, даже неверные, пробе не мешают.
+	portEnv := envFile("port.env", "HTTP_PORT="+port, "DATABASE_PORT=x")
+	badPortEnv := envFile("bad-port.env", "HTTP_PORT=99999")
+
+	tests := []struct {
+		name  string
+		args  []string
+		ready bool
+		want  int
+	}{
+		{name: "live", args: []string{"probe", "-addr", addr, "-env-file", missing}, want: 0},
+		{name: "ready", args: []string{"probe", "-check", "ready", "-addr", addr, "-env-file", missing}, ready: true, want: 0},
+		{name: "not ready", args: []string{"probe", "-check", "ready", "-addr", addr, "-env-file", missing}, want: 1},
+		{name: "port from env file", args: []string{"probe", "-env-file", portEnv}, want: 0},
+		{name: "invalid port in env file", args: []string{"probe", "-env-file", badPortEnv}, want: 1},
+		{name: "unsupported check", args: []string{"probe", "-check", "startup", "-addr", addr}, want: 1},
+		{name: "unsupported transport", args: []string{"probe", "-transport", "tcp", "-addr", addr, "-env-file", missing}, want: 1},
+		{name: "unexpected argument", args: []string{"probe", "ready"}, want: 1},
+		{name: "unknown flag", args: []string{"probe", "-port", "8080"}, want: 1},
+		{name: "grpc against an http server", args: []string{"probe", "-transport", "grpc", "-addr", addr, "-timeout", "200ms", "-env-file", missing}, want: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ready.Store(tt.ready)
+			if got := runProbe(tt.args); got != tt.want {
+				t.Errorf("runProbe = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,188 @@
+package main
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/desulaidovich/app/config"
+	"github.com/desulaidovich/app/pkg/cli"
+	"github.com/desulaidovich/app/pkg/env"
+)
+
+func configCommand(t *tool) *cli.Command {
+	cmd := &cli.Command{
+		Name:  "config",
+		Short: "Inspect and validate the application configuration",
+	}
+	cmd.Add(
+		configPrintCommand(t),
+		configValidateCommand(t),
+		configDocCommand(),
+	)
+	return cmd
+}
+
+func configPrintCommand(t *tool) *cli.Command {
+	cmd := &cli.Command{
+		Name:  "print",
+		Short: "Print the effective configuration and where each value comes from",
+		Long: `Print the effective configuration and where each value comes from.
+
+The source is one of: default (struct tag default), file (the -env-file dotenv
+file), env (OS environment, overrides the file) or unset. Values read from a file
+through <KEY>_FILE show the file path next to the source. Secrets are redacted.`,
+	}
+	output := cmd.Flags().String("output", "table", "output format (table, json)")
+
+	cmd.Run = func(_ context.Context, args []string) error {
+		if len(args) != 0 {
+			return cli.Usagef("unexpected arguments: %v", args)
+		}
+		if *output != "table" && *output != "json" {
+			return cli.Usagef("unsupported output format: %q (valid: table, json)", *output)
+		}
+
+		vars, err := env.Inspect(config.Config{}, t.envFile)
+		if err != nil {
+			return fmt.Errorf("failed to inspect config: %w", err)
+		}
+
+		if *output == "json" {
+			for i := range vars {
+				vars[i].Value = vars[i].Display()
+			}
+			enc := json.NewEncoder(os.Stdout)
+			enc.SetIndent("", "  ")
+			return enc.Encode(vars)
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "KEY\tVALUE\tSOURCE")
+		for _, v := range vars {
+			source := string(v.Source)
+			if v.File != "" {
+				source += " (" + v.File + ")"
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\n", v.Key, cmp.Or(v.Display(), "-"), source)
+		}
+		return w.Flush()
+	}
+	return cmd
+}
+
+func configValidateCommand(t *tool) *cli.Command {
+	return &cli.Command{
+		Name:  "validate",
+		Short: "Check that the configuration loads and has no unknown variables",
+		Long: fmt.Sprintf(`Check that the configuration loads and has no unknown variables.
+
+Reports values that fail to parse, missing required variables and variables that
+share a prefix with the configuration (e.g. DATABASE_) but match no field, which
+is usually a typo. Exits with code %d if any problem is found.`, exitCheck),
+		Run: func(_ context.Context, args []string) error {
+			if len(args) != 0 {
+				return cli.Usagef("unexpected arguments: %v", args)
+			}
+
+			var problems []string
+			var cfg config.Config
+			if err := env.Load(&cfg, t.envFile); err != nil {
+				problems = append(problems, strings.Split(err.Error(), "\n")...)
+			}
+
+			unknown, err := env.Unknown(config.Config{}, t.envFile)
+			if err != nil {
+				return fmt.Errorf("failed to inspect config: %w", err)
+			}
+			for _, u := range unknown {
+				problem := fmt.Sprintf("unknown env: %s (from %s)", u.Key, u.Source)
+				if u.Suggestion != "" {
+					problem += fmt.Sprintf(", did you mean %s?", u.Suggestion)
+				}
+				problems = append(problems, problem)
+			}
+
+			if len(problems) > 0 {
+				for _, p := range problems {
+					fmt.Fprintln(os.Stderr, p)
+				}
+				return cli.Exit(exitCheck, fmt.Errorf("invalid config: %d problem(s)", len(problems)))
+			}
+
+			fmt.Println("Config is valid")
+			return nil
+		},
+	}
+}
+
+func configDocCommand() *cli.Command {
+	cmd := &cli.Command{
+		Name:  "doc",
+		Short: "Generate the environment variable reference",
+		Long: `Generate the environment variable reference from config.Config struct tags.
+
+markdown prints the table used in README.md, env prints a dotenv template with
+defaults filled in.`,
+	}
+	format := cmd.Flags().String("format", "markdown", "output format (markdown, env)")
+
+	cmd.Run = func(_ context.Context, args []string) error {
+		if len(args) != 0 {
+			return cli.Usagef("unexpected arguments: %v", args)
+		}
+
+		vars, err := env.Describe(config.Config{})
+		if err != nil {
+			return fmt.Errorf("failed to describe config: %w", err)
+		}
+
+		switch *format {
+		case "markdown":
+			printMarkdownDoc(vars)
+		case "env":
+			printEnvDoc(vars)
+		default:
+			return cli.Usagef("unsupported format: %q (valid: markdown, env)", *format)
+		}
+		return nil
+	}
+	return cmd
+}
+
+func printMarkdownDoc(vars []env.Var) {
+	fmt.Println("| Переменная | По умолчанию | Описание |")
+	fmt.Println("|---|---|---|")
+	for _, v := range vars {
+		def := "—"
+		if v.Default != "" {
+			def = "`" + v.Default + "`"
+		}
+		desc := v.Description
+		if v.Required {
+			desc = strings.TrimSpace(desc + " (обязательная)")
+		}
+		fmt.Printf("| `%s` | %s | %s |\n", v.Key, def, desc)
+	}
+}
+
+func printEnvDoc(vars []env.Var) {
+	var section string
+	for _, v := range vars {
+		if prefix, _, _ := strings.Cut(v.Key, "_"); prefix != section {
+			if section != "" {
+				fmt.Println()
+			}
+			section = prefix
+			fmt.Printf("# %s_\n", section)
+		}
+		if v.Description != "" {
+			fmt.Printf("# %s\n", v.Description)
+		}
+		fmt.Printf("%s=%s\n", v.Key, v.Default)
+	}
+}
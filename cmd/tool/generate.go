@@ -0,0 +1,58 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/desulaidovich/app/internal/scaffold"
+	"github.com/desulaidovich/app/pkg/cli"
+)
+
+func generateCommand() *cli.Command {
+	cmd := &cli.Command{
+		Name:  "generate",
+		Short: "Generate code skeletons",
+	}
+	cmd.Add(generateServiceCommand())
+	return cmd
+}
+
+func generateServiceCommand() *cli.Command {
+	cmd := &cli.Command{
+		Name:  "service",
+		Args:  "<name>",
+		Short: "Generate a new API service",
+		Long: `Generate a new API service.
+
+<name> is the singular entity name in lowercase letters and digits, e.g. order.
+The command creates:
+
+  proto/<name>/v1/<name>.proto   service with List, Get and Create RPCs
+  internal/<name>/repository.go  repository over postgres.TxManager
+  internal/handler/<name>.go     handler implementing app.Service
+  migrations/<version>_create_<names>.sql
+
+and adds the handler to app.WithServices in cmd/app/main.go.
+
+Nothing is overwritten. Run 'make proto' afterwards to generate api/<name>/v1.`,
+	}
+	root := cmd.Flags().String("root", ".", "module root containing go.mod")
+
+	cmd.Run = func(_ context.Context, args []string) error {
+		if len(args) != 1 {
+			return cli.Usagef("expected exactly one service name")
+		}
+
+		created, err := scaffold.Service{Name: args[0], Root: *root}.Generate()
+		for _, path := range created {
+			fmt.Println("wrote", path)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to generate service: %w", err)
+		}
+
+		fmt.Println("\nNext: run 'make proto', then 'go build ./...'.")
+		return nil
+	}
+	return cmd
+}
@@ -2,124 +2,110 @@ package main
 
 import (
 	"context"
-	"flag"
 	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
 
 	_ "github.com/jackc/pgx/v5/stdlib"
 
 	"github.com/desulaidovich/app/config"
 	"github.com/desulaidovich/app/internal/migrator"
+	"github.com/desulaidovich/app/pkg/cli"
 	"github.com/desulaidovich/app/pkg/env"
 	"github.com/desulaidovich/app/pkg/log"
 )
 
+// exitCheck — код завершения проверок (status -check, verify), нашедших расхождения.
+const exitCheck = 3
+
 func main() {
-	ctx := context.Background()
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
 
-	var cfg config.Config
-	if err := env.Load(&cfg, ".env"); err != nil {
-		panic("failed to load config: " + err.Error())
+	code := cli.Execute(ctx, newRoot(), os.Args[1:])
+	stop()
+	os.Exit(code)
+}
+
+func newRoot() *cli.Command {
+	t := &tool{}
+
+	root := &cli.Command{
+		Name:  "tool",
+		Short: "Development and operations tool for the application",
 	}
+	root.Flags().StringVar(&t.envFile, "env-file", ".env", "dotenv file to load configuration from")
 
-	logger, err := log.New(
-		log.WithLevel(cfg.Log.Level),
-		log.WithFormat(cfg.Log.Format),
-		log.WithTimeFormat(cfg.Log.TimeFormat),
+	root.Add(
+		migrateCommand(t),
+		seedCommand(t),
+		configCommand(t),
+		generateCommand(),
 	)
-	if err != nil {
-		panic("failed to create logger: " + err.Error())
-	}
+	root.Add(cli.CompletionCommand(root))
+	return root
+}
 
-	cmd := flag.String("cmd", "", "Команда на выполнение")
-	param := flag.String("param", "", "Параметры (up/down/status/create)")
-	name := flag.String("name", "", "Название миграции")
+// tool лениво загружает общие для команд конфигурацию и логгер: команды, которым
+// они не нужны (help, completion, create), работают без .env.
+type tool struct {
+	envFile string
 
-	flag.Parse()
+	cfg    *config.Config
+	logger log.Logger
+}
 
-	if *cmd == "" {
-		panic("Команда не указана")
+func (t *tool) config() (*config.Config, error) {
+	if t.cfg != nil {
+		return t.cfg, nil
 	}
 
-	m, err := migrator.New(cfg.DSN())
-	if err != nil {
-		panic("failed to init migrator: " + err.Error())
+	var cfg config.Config
+	if err := env.Load(&cfg, t.envFile); err != nil {
+		return nil, fmt.Errorf("failed to load config: %w", err)
 	}
+	t.cfg = &cfg
+	return t.cfg, nil
+}
 
-	commands := map[string]func() error{
-		"migrate": func() error {
-			return handleMigrate(ctx, m, logger, *param, *name)
-		},
+func (t *tool) log() (log.Logger, error) {
+	if t.logger != nil {
+		return t.logger, nil
 	}
 
-	handler, exists := commands[*cmd]
-	if !exists {
-		panic("unknown command: " + *cmd)
+	cfg, err := t.config()
+	if err != nil {
+		return nil, err
 	}
 
-	if err := handler(); err != nil {
-		panic("failed to run command: " + err.Error())
+	t.logger, err = log.New(
+		log.WithLevel(cfg.Log.Level),
+		log.WithFormat(cfg.Log.Format),
+		log.WithTimeFormat(cfg.Log.TimeFormat),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create logger: %w", err)
 	}
+	return t.logger, nil
 }
 
-func handleMigrate(ctx context.Context, m *migrator.Migrator, logger log.Logger, param, name string) error {
-	if param == "" {
-		return fmt.Errorf("empty migration parameter")
+// migrator открывает мигратор по конфигурации. Закрывает его вызывающий.
+func (t *tool) migrator() (*migrator.Migrator, log.Logger, error) {
+	cfg, err := t.config()
+	if err != nil {
+		return nil, nil, err
 	}
-
-	migrateCommands := map[string]func() error{
-		"create": func() error {
-			if name == "" {
-				return fmt.Errorf("empty migration name")
-			}
-
-			if err := m.Create(name); err != nil {
-				return fmt.Errorf("failed to create migration: %w", err)
-			}
-
-			logger.With(map[string]any{
-				"name": name,
-			}).Info("Migration created successfully")
-			return nil
-		},
-		"up": func() error {
-			if err := m.Up(ctx); err != nil {
-				return fmt.Errorf("failed to apply migrations: %w", err)
-			}
-
-			logger.Info("Migrations applied successfully")
-			return nil
-		},
-		"down": func() error {
-			if err := m.Down(ctx); err != nil {
-				return fmt.Errorf("failed to rollback migrations: %w", err)
-			}
-
-			logger.Info("Migrations rolled back successfully")
-			return nil
-		},
-		"status": func() error {
-			if err := m.Status(ctx); err != nil {
-				return fmt.Errorf("failed to get migration status: %w", err)
-			}
-			return nil
-		},
-		"version": func() error {
-			version, err := m.Version(ctx)
-			if err != nil {
-				return fmt.Errorf("failed to get migration version: %w", err)
-			}
-
-			logger.With(map[string]any{
-				"version": version,
-			}).Info("Current migration version")
-			return nil
-		},
+	logger, err := t.log()
+	if err != nil {
+		return nil, nil, err
 	}
 
-	handler, exists := migrateCommands[param]
-	if !exists {
-		return fmt.Errorf("unknown migration command: %s", param)
+	m, err := migrator.New(cfg.DSN(),
+		migrator.WithLogger(logger),
+		migrator.WithLockTimeout(cfg.App.MigrateLockTimeout),
+	)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to init migrator: %w", err)
 	}
-
-	return handler()
+	return m, logger, nil
 }
@@ -0,0 +1,520 @@
+package main
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	"github.com/desulaidovich/app/internal/migrator"
+	"github.com/desulaidovich/app/migrations"
+	"github.com/desulaidovich/app/pkg/cli"
+	"github.com/desulaidovich/app/pkg/log"
+)
+
+type applyFunc func(ctx context.Context, m *migrator.Migrator, target int64) ([]migrator.Result, error)
+
+func migrateCommand(t *tool) *cli.Command {
+	cmd := &cli.Command{
+		Name:  "migrate",
+		Short: "Manage database migrations",
+	}
+
+	cmd.Add(
+		applyCommand(t, migrator.CommandUp, "Apply all pending migrations", false,
+			func(ctx context.Context, m *migrator.Migrator, _ int64) ([]migrator.Result, error) {
+				return m.Up(ctx)
+			}),
+		applyCommand(t, migrator.CommandUpByOne, "Apply the next pending migration", false,
+			func(ctx context.Context, m *migrator.Migrator, _ int64) ([]migrator.Result, error) {
+				return single(m.UpByOne(ctx))
+			}),
+		applyCommand(t, migrator.CommandUpTo, "Apply pending migrations up to and including a version", true,
+			func(ctx context.Context, m *migrator.Migrator, target int64) ([]migrator.Result, error) {
+				return m.UpTo(ctx, target)
+			}),
+		applyCommand(t, migrator.CommandDown, "Roll back the latest applied migration", false,
+			func(ctx context.Context, m *migrator.Migrator, _ int64) ([]migrator.Result, error) {
+				return single(m.Down(ctx))
+			}),
+		applyCommand(t, migrator.CommandDownTo, "Roll back migrations newer than a version", true,
+			func(ctx context.Context, m *migrator.Migrator, target int64) ([]migrator.Result, error) {
+				return m.DownTo(ctx, target)
+			}),
+		applyCommand(t, migrator.CommandRedo, "Roll back and re-apply the latest migration", false,
+			func(ctx context.Context, m *migrator.Migrator, _ int64) ([]migrator.Result, error) {
+				return m.Redo(ctx)
+			}),
+		applyCommand(t, migrator.CommandReset, "Roll back all applied migrations", false,
+			func(ctx context.Context, m *migrator.Migrator, _ int64) ([]migrator.Result, error) {
+				return m.Reset(ctx)
+			}),
+		statusCommand(t),
+		versionCommand(t),
+		createCommand(t),
+		validateCommand(t),
+		fixCommand(t),
+		verifyCommand(t),
+		snapshotCommand(t),
+	)
+	return cmd
+}
+
+// applyCommand описывает команду, меняющую схему. С withTarget команда принимает
+// целевую версию позиционным аргументом.
+func applyCommand(t *tool, name migrator.Command, short string, withTarget bool, apply applyFunc) *cli.Command {
+	cmd := &cli.Command{
+		Name:  string(name),
+		Short: short,
+	}
+	if withTarget {
+		cmd.Args = "<version>"
+	}
+	dryRun := cmd.Flags().Bool("dry-run", false, "print the SQL that would run without applying it")
+
+	cmd.Run = func(ctx context.Context, args []string) error {
+		var target int64
+		switch {
+		case withTarget && len(args) != 1:
+			return cli.Usagef("expected exactly one version")
+		case withTarget:
+			v, err := strconv.ParseInt(args[0], 10, 64)
+			if err != nil || v < 0 {
+				return cli.Usagef("invalid version: %q", args[0])
+			}
+			target = v
+		case len(args) != 0:
+			return cli.Usagef("unexpected arguments: %v", args)
+		}
+
+		m, logger, err := t.migrator()
+		if err != nil {
+			return err
+		}
+		defer m.Close()
+
+		if *dryRun {
+			steps, err := m.Plan(ctx, name, target)
+			if err != nil {
+				return fmt.Errorf("failed to plan migrations: %w", err)
+			}
+			printPlan(steps)
+			return nil
+		}
+
+		results, err := apply(ctx, m, target)
+		logResults(logger, results)
+		if err != nil {
+			return fmt.Errorf("failed to run %s: %w", name, err)
+		}
+
+		logger.With(map[string]any{
+			"command":    name,
+			"migrations": len(results),
+		}).Info("Migrations completed successfully")
+		return nil
+	}
+	return cmd
+}
+
+func statusCommand(t *tool) *cli.Command {
+	cmd := &cli.Command{
+		Name:  "status",
+		Short: "Show applied and pending migrations",
+		Long: fmt.Sprintf(`Show applied and pending migrations.
+
+With -check the command exits with code %d if any migration is pending.`, exitCheck),
+	}
+	output := cmd.Flags().String("output", "table", "output format (table, json)")
+	check := cmd.Flags().Bool("check", false, "exit with a non-zero code if migrations are pending")
+
+	cmd.Run = func(ctx context.Context, args []string) error {
+		if len(args) != 0 {
+			return cli.Usagef("unexpected arguments: %v", args)
+		}
+		if *output != "table" && *output != "json" {
+			return cli.Usagef("unsupported output format: %q (valid: table, json)", *output)
+		}
+
+		m, _, err := t.migrator()
+		if err != nil {
+			return err
+		}
+		defer m.Close()
+
+		statuses, err := m.Status(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to get migration status: %w", err)
+		}
+		if err := printStatus(os.Stdout, statuses, *output); err != nil {
+			return err
+		}
+
+		if *check {
+			var pending int
+			for _, s := range statuses {
+				if s.Pending {
+					pending++
+				}
+			}
+			if pending > 0 {
+				return cli.Exit(exitCheck, fmt.Errorf("pending migrations: %d", pending))
+			}
+		}
+		return nil
+	}
+	return cmd
+}
+
+func printStatus(out io.Writer, statuses []migrator.MigrationStatus, output string) error {
+	if output == "json" {
+		enc := json.NewEncoder(out)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(statuses); err != nil {
+			return fmt.Errorf("failed to encode status: %w", err)
+		}
+		return nil
+	}
+
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "VERSION\tNAME\tTYPE\tSTATE\tAPPLIED AT")
+	for _, s := range statuses {
+		name, state, appliedAt := cmp.Or(s.Name, "-"), "pending", "-"
+		if !s.Pending {
+			state, appliedAt = "applied", s.AppliedAt.Format(time.RFC3339)
+		}
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\n", s.Version, name, s.Type, state, appliedAt)
+	}
+	return w.Flush()
+}
+
+func versionCommand(t *tool) *cli.Command {
+	return &cli.Command{
+		Name:  "version",
+		Short: "Print the current schema version",
+		Run: func(ctx context.Context, args []string) error {
+			if len(args) != 0 {
+				return cli.Usagef("unexpected arguments: %v", args)
+			}
+
+			m, _, err := t.migrator()
+			if err != nil {
+				return err
+			}
+			defer m.Close()
+
+			version, err := m.Version(ctx)
+			if err != nil {
+				return fmt.Errorf("failed to get migration version: %w", err)
+			}
+			fmt.Println(version)
+			return nil
+		},
+	}
+}
+
+func createCommand(t *tool) *cli.Command {
+	cmd := &cli.Command{
+		Name:  "create",
+		Args:  "<name>",
+		Short: "Create a new migration file",
+	}
+	typ := cmd.Flags().String("type", string(migrator.TypeSQL), "migration type (sql, go)")
+	dir := cmd.Flags().String("dir", "", "migrations directory (default: "+migrator.Directory+" in the module root)")
+
+	cmd.Run = func(_ context.Context, args []string) error {
+		if len(args) != 1 {
+			return cli.Usagef("expected exactly one migration name")
+		}
+		if migrator.Type(*typ) != migrator.TypeSQL && migrator.Type(*typ) != migrator.TypeGo {
+			return cli.Usagef("unsupported migration type: %q (valid: sql, go)", *typ)
+		}
+
+		dir, err := migrationsDir(*dir)
+		if err != nil {
+			return err
+		}
+
+		path, err := migrator.Create(dir, args[0], migrator.Type(*typ))
+		if err != nil {
+			return fmt.Errorf("failed to create migration: %w", err)
+		}
+		fmt.Println(path)
+		return nil
+	}
+	return cmd
+}
+
+// migrationsDir возвращает dir, если он задан, иначе каталог миграций в корне модуля:
+// go.mod ищется вверх от текущего каталога, поэтому create и fix работают из любого подкаталога.
+func migrationsDir(dir string) (string, error) {
+	if dir != "" {
+		return dir, nil
+	}
+
+	wd, err := os.Getwd()
+	if err != nil {
+		return "", fmt.Errorf("failed to get working directory: %w", err)
+	}
+	for root := wd; ; {
+		if _, err := os.Stat(filepath.Join(root, "go.mod")); err == nil {
+			return filepath.Join(root, migrator.Directory), nil
+		}
+		parent := filepath.Dir(root)
+		if parent == root {
+			return "", cli.Usagef("module root with go.mod not found above %s, pass -dir", wd)
+		}
+		root = parent
+	}
+}
+
+func validateCommand(_ *tool) *cli.Command {
+	return &cli.Command{
+		Name:  "validate",
+		Short: "Check migration files for duplicate versions and annotation errors",
+		Run: func(_ context.Context, args []string) error {
+			if len(args) != 0 {
+				return cli.Usagef("unexpected arguments: %v", args)
+			}
+
+			if err := migrator.Validate(migrations.FS, migrations.GoMigrations()); err != nil {
+				return fmt.Errorf("invalid migrations:\n%w", err)
+			}
+			fmt.Println("Migrations are valid")
+			return nil
+		},
+	}
+}
+
+func fixCommand(t *tool) *cli.Command {
+	cmd := &cli.Command{
+		Name:  "fix",
+		Short: "Renumber timestamped migrations into sequential versions",
+		Long: `Renumber timestamped migrations into sequential versions.
+
+Connects to the database and refuses to rename migrations that are already
+applied: goose would treat the renamed file as a new migration and apply it again.`,
+	}
+	dryRun := cmd.Flags().Bool("dry-run", false, "print the renames without applying them")
+	dir := cmd.Flags().String("dir", "", "migrations directory (default: "+migrator.Directory+" in the module root)")
+
+	cmd.Run = func(ctx context.Context, args []string) error {
+		if len(args) != 0 {
+			return cli.Usagef("unexpected arguments: %v", args)
+		}
+
+		dir, err := migrationsDir(*dir)
+		if err != nil {
+			return err
+		}
+
+		m, _, err := t.migrator()
+		if err != nil {
+			return err
+		}
+		defer m.Close()
+
+		applied, err := m.Applied(ctx)
+		if err != nil {
+			return err
+		}
+
+		fix := migrator.Fix
+		if *dryRun {
+			fix = migrator.PlanFix
+		}
+
+		renames, err := fix(dir, applied)
+		for _, r := range renames {
+			fmt.Printf("%s -> %s\n", r.From, r.To)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to fix migrations: %w", err)
+		}
+		return nil
+	}
+	return cmd
+}
+
+func verifyCommand(t *tool) *cli.Command {
+	cmd := &cli.Command{
+		Name:  "verify",
+		Short: "Detect drift between applied migrations and migration files",
+		Long: fmt.Sprintf(`Detect drift between applied migrations and migration files.
+
+Reports applied migrations whose files changed, known migrations skipped in the
+database and applied versions without a source. With -snapshot the live schema
+is also compared to a committed snapshot. Exits with code %d on drift.`, exitCheck),
+	}
+	snapshot := cmd.Flags().String("snapshot", "", "schema snapshot to compare the live schema with")
+
+	cmd.Run = func(ctx context.Context, args []string) error {
+		if len(args) != 0 {
+			return cli.Usagef("unexpected arguments: %v", args)
+		}
+
+		m, logger, err := t.migrator()
+		if err != nil {
+			return err
+		}
+		defer m.Close()
+
+		return verify(ctx, m, logger, *snapshot)
+	}
+	return cmd
+}
+
+func verify(ctx context.Context, m *migrator.Migrator, logger log.Logger, snapshot string) error {
+	report, err := m.Verify(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to verify migrations: %w", err)
+	}
+
+	ok := report.OK()
+	for _, v := range report.Modified {
+		logger.With(map[string]any{"version": v}).Error("Applied migration was modified")
+	}
+	for _, v := range report.Missing {
+		logger.With(map[string]any{"version": v}).Error("Migration is missing from the database")
+	}
+	for _, v := range report.Unknown {
+		logger.With(map[string]any{"version": v}).Error("Applied version is unknown")
+	}
+	for _, v := range report.Unrecorded {
+		logger.With(map[string]any{"version": v}).Warn("Applied migration has no recorded checksum")
+	}
+
+	if snapshot != "" {
+		f, err := os.Open(snapshot)
+		if err != nil {
+			return fmt.Errorf("failed to open schema snapshot: %w", err)
+		}
+		defer f.Close()
+
+		expected, err := migrator.ReadSnapshot(f)
+		if err != nil {
+			return fmt.Errorf("failed to read schema snapshot: %w", err)
+		}
+		actual, err := m.Snapshot(ctx)
+		if err != nil {
+			return err
+		}
+
+		missing, unexpected := migrator.CompareSnapshot(expected, actual)
+		for _, line := range missing {
+			logger.With(map[string]any{"object": line}).Error("Schema object is missing")
+		}
+		for _, line := range unexpected {
+			logger.With(map[string]any{"object": line}).Error("Schema object is not in snapshot")
+		}
+		ok = ok && len(missing) == 0 && len(unexpected) == 0
+	}
+
+	if !ok {
+		return cli.Exit(exitCheck, errors.New("migration drift detected"))
+	}
+
+	logger.Info("No migration drift detected")
+	return nil
+}
+
+func snapshotCommand(t *tool) *cli.Command {
+	cmd := &cli.Command{
+		Name:  "snapshot",
+		Short: "Write the live schema to a snapshot file",
+	}
+	out := cmd.Flags().String("out", "",
+		"snapshot file to write (default: "+migrator.Directory+"/"+migrator.SnapshotFile+" in the module root)")
+
+	cmd.Run = func(ctx context.Context, args []string) error {
+		if len(args) != 0 {
+			return cli.Usagef("unexpected arguments: %v", args)
+		}
+
+		path := *out
+		if path == "" {
+			dir, err := migrationsDir("")
+			if err != nil {
+				return err
+			}
+			path = filepath.Join(dir, migrator.SnapshotFile)
+		}
+
+		m, logger, err := t.migrator()
+		if err != nil {
+			return err
+		}
+		defer m.Close()
+
+		lines, err := m.Snapshot(ctx)
+		if err != nil {
+			return err
+		}
+
+		f, err := os.Create(path)
+		if err != nil {
+			return fmt.Errorf("failed to create schema snapshot: %w", err)
+		}
+		defer f.Close()
+
+		if err := migrator.WriteSnapshot(f, lines); err != nil {
+			return fmt.Errorf("failed to write schema snapshot: %w", err)
+		}
+
+		logger.With(map[string]any{
+			"path":    path,
+			"objects": len(lines),
+		}).Info("Schema snapshot written")
+		return nil
+	}
+	return cmd
+}
+
+// printPlan печатает SQL, который выполнила бы команда, в stdout.
+func printPlan(steps []migrator.Step) {
+	if len(steps) == 0 {
+		fmt.Println("-- no migrations to run")
+		return
+	}
+
+	for _, step := range steps {
+		fmt.Printf("-- %d %s (%s)\n", step.Version, step.Path, step.Direction)
+		if step.Type == migrator.TypeGo {
+			fmt.Printf("-- go migration, SQL is not available\n\n")
+			continue
+		}
+		if step.NoTx {
+			fmt.Println("-- +goose NO TRANSACTION")
+		}
+		for _, stmt := range step.Statements {
+			fmt.Println(stmt)
+		}
+		fmt.Println()
+	}
+}
+
+func single(result *migrator.Result, err error) ([]migrator.Result, error) {
+	if result == nil {
+		return nil, err
+	}
+	return []migrator.Result{*result}, err
+}
+
+func logResults(logger log.Logger, results []migrator.Result) {
+	for _, r := range results {
+		logger.With(map[string]any{
+			"version":   r.Version,
+			"path":      r.Path,
+			"direction": r.Direction,
+			"duration":  r.Duration.String(),
+			"empty":     r.Empty,
+		}).Info("Migration")
+	}
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/desulaidovich/app/internal/migrator"
+)
+
+func TestPrintStatus(t *testing.T) {
+	appliedAt := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+	statuses := []migrator.MigrationStatus{
+		{Version: 1, Name: "init", Type: migrator.TypeSQL, Path: "00001_init.sql", AppliedAt: &appliedAt},
+		{Version: 20261018120000, Type: migrator.TypeGo, Pending: true},
+	}
+
+	tests := []struct {
+		name     string
+		statuses []migrator.MigrationStatus
+		output   string
+		want     string
+	}{
+		{
+			name:     "table",
+			statuses: statuses,
+			output:   "table",
+			want: "VERSION         NAME  TYPE  STATE    APPLIED AT\n" +
+				"1               init  sql   applied  2026-10-18T12:00:00Z\n" +
+				"20261018120000  -     go    pending  -\n",
+		},
+		{
+			name:   "empty table",
+			output: "table",
+			want:   "VERSION  NAME  TYPE  STATE  APPLIED AT\n",
+		},
+		{
+			name:     "json",
+			statuses: statuses,
+			output:   "json",
+			want: `[
+  {
+    "version": 1,
+    "name": "init",
+    "type": "sql",
+    "path": "00001_init.sql",
+    "applied_at": "2026-10-18T12:00:00Z",
+    "pending": false
+  },
+  {
+    "version": 20261018120000,
+    "name": "",
+    "type": "go",
+    "applied_at": null,
+    "pending": true
+  }
+]
+`,
+		},
+		{
+			name:     "empty json",
+			statuses: []migrator.MigrationStatus{},
+			output:   "json",
+			want:     "[]\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := printStatus(&buf, tt.statuses, tt.output); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if buf.String() != tt.want {
+				t.Errorf("output:\n%s\nwant:\n%s", buf.String(), tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/desulaidovich/app/internal/seeder"
+	"github.com/desulaidovich/app/pkg/cli"
+)
+
+func seedCommand(t *tool) *cli.Command {
+	cmd := &cli.Command{
+		Name:  "seed",
+		Short: "Load seed data into the database",
+		Long: `Load seed data into the database.
+
+The seed set defaults to APP_ENV. Applied seeds are remembered, so running the
+command again only applies new ones. Seeding is refused in production.`,
+	}
+	set := cmd.Flags().String("set", "", "seed set (development, test), defaults to APP_ENV")
+	reset := cmd.Flags().Bool("reset", false, "roll back and re-apply all migrations before seeding")
+
+	cmd.Run = func(ctx context.Context, args []string) error {
+		if len(args) != 0 {
+			return cli.Usagef("unexpected arguments: %v", args)
+		}
+
+		cfg, err := t.config()
+		if err != nil {
+			return err
+		}
+
+		var opts []seeder.Option
+		if *set != "" {
+			opts = append(opts, seeder.WithSet(*set))
+		}
+
+		s, err := seeder.New(cfg.DSN(), cfg.App.Env, opts...)
+		if err != nil {
+			return fmt.Errorf("failed to init seeder: %w", err)
+		}
+		defer s.Close()
+
+		m, logger, err := t.migrator()
+		if err != nil {
+			return err
+		}
+		defer m.Close()
+
+		if *reset {
+			results, err := m.Reset(ctx)
+			logResults(logger, results)
+			if err != nil {
+				return fmt.Errorf("failed to reset database: %w", err)
+			}
+
+			results, err = m.Up(ctx)
+			logResults(logger, results)
+			if err != nil {
+				return fmt.Errorf("failed to apply migrations: %w", err)
+			}
+
+			if err := s.Forget(ctx); err != nil {
+				return err
+			}
+		}
+
+		results, err := s.Seed(ctx)
+		for _, r := range results {
+			logger.With(map[string]any{
+				"version":  r.Version,
+				"path":     r.Path,
+				"duration": r.Duration.String(),
+			}).Info("Seed applied")
+		}
+		if err != nil {
+			return err
+		}
+
+		logger.With(map[string]any{
+			"set":     s.Set(),
+			"applied": len(results),
+		}).Info("Database seeded successfully")
+		return nil
+	}
+	return cmd
+}
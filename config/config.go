@@ -1,57 +1,137 @@
 package config
 
 import (
+	"errors"
 	"fmt"
+	"net"
+	"strconv"
 	"time"
+
+	"github.com/desulaidovich/app/pkg/env"
 )
 
+type HTTP struct {
+	Port int `env:"PORT,default=8080,min=1,max=65535" desc:"Порт grpc-gateway"`
+}
+
+type GRPC struct {
+	Port int `env:"PORT,default=9090,min=1,max=65535" desc:"Порт gRPC"`
+}
+
+// Ports — часть Config с портами сервера. Её загружает проба здоровья, которой
+// не нужны остальные переменные, в том числе обязательные.
+type Ports struct {
+	HTTP HTTP `env:"HTTP"`
+	GRPC GRPC `env:"GRPC"`
+}
+
 type Config struct {
 	App struct {
-		Name  string `env:"NAME"`
-		Env   string `env:"ENV,default=development"`
-		Debug bool   `env:"DEBUG"`
+		Name               string        `env:"NAME" desc:"Имя приложения"`
+		Env                string        `env:"ENV,default=development,oneof=development test production" desc:"Окружение: development / test / production"`
+		Debug              bool          `env:"DEBUG" desc:"Включает gRPC reflection и логирование каждого SQL-запроса"`
+		MigrateOnStart     bool          `env:"MIGRATE_ON_START" desc:"Применяет встроенные миграции перед запуском"`
+		MigrateLockTimeout time.Duration `env:"MIGRATE_LOCK_TIMEOUT,default=5m,min=1s" desc:"Сколько ждать блокировку миграций, занятую другим экземпляром"`
+		SchemaCheck        string        `env:"SCHEMA_CHECK,default=fail,oneof=fail warn" desc:"Реакция на отставание схемы БД от встроенных миграций: fail / warn"`
+		AdminToken         env.Secret    `env:"ADMIN_TOKEN" desc:"Bearer-токен административных API jobs и scheduler, пустой отключает их"`
+		DisabledServices   []string      `env:"DISABLED_SERVICES,regex=^[a-z][a-z0-9]*$" desc:"API-сервисы, которые не регистрируются, через запятую: health / jobs / scheduler"`
 	} `env:"APP"`
 
-	HTTP struct {
-		Port string `env:"PORT,default=8080"`
-	} `env:"HTTP"`
-
-	GRPC struct {
-		Port string `env:"PORT,default=9090"`
-	} `env:"GRPC"`
+	HTTP HTTP `env:"HTTP"`
+	GRPC GRPC `env:"GRPC"`
 
 	Database struct {
-		Host    string `env:"HOST"`
-		Port    int    `env:"PORT,default=5432"`
-		Name    string `env:"NAME"`
-		SSLMode string `env:"SSL_MODE,default=disable"`
+		Host    string `env:"HOST" desc:"Хост PostgreSQL"`
+		Port    int    `env:"PORT,default=5432,min=1,max=65535" desc:"Порт PostgreSQL"`
+		Name    string `env:"NAME" desc:"Имя базы данных"`
+		SSLMode string `env:"SSL_MODE,default=disable,oneof=disable require verify-ca verify-full" desc:"Режим SSL: disable / require / verify-ca / verify-full"`
 		User    struct {
-			Name     string `env:"NAME"`
-			Password string `env:"PASSWORD"`
+			Name     string     `env:"NAME" desc:"Пользователь базы данных"`
+			Password env.Secret `env:"PASSWORD" desc:"Пароль пользователя базы данных, можно из файла через DATABASE_USER_PASSWORD_FILE"`
 		}
 		Pool struct {
-			MaxConns        int32         `env:"MAX_CONNS,default=25"`
-			MinConns        int32         `env:"MIN_CONNS,default=5"`
-			MaxConnLifetime time.Duration `env:"MAX_CONN_LIFETIME,default=5m"`
-			MaxConnIdleTime time.Duration `env:"MAX_CONN_IDLE_TIME,default=5m"`
-			ConnectTimeout  time.Duration `env:"CONNECT_TIMEOUT,default=5s"`
+			MaxConns        int32         `env:"MAX_CONNS,default=25,min=1" desc:"Максимум соединений в пуле"`
+			MinConns        int32         `env:"MIN_CONNS,default=5,min=0" desc:"Минимум соединений в пуле"`
+			MaxConnLifetime time.Duration `env:"MAX_CONN_LIFETIME,default=5m" desc:"Время жизни соединения"`
+			MaxConnIdleTime time.Duration `env:"MAX_CONN_IDLE_TIME,default=5m" desc:"Время простоя, после которого соединение закрывается"`
+			ConnectTimeout  time.Duration `env:"CONNECT_TIMEOUT,default=5s" desc:"Таймаут подключения"`
 		}
+		Replicas struct {
+			Hosts       []string      `env:"HOSTS" desc:"Реплики для чтения через запятую, host или host:port"`
+			Strategy    string        `env:"STRATEGY,default=round-robin,oneof=round-robin least-connections" desc:"Выбор реплики: round-robin / least-connections"`
+			MaxLag      time.Duration `env:"MAX_LAG,default=10s" desc:"Допустимое отставание реплики"`
+			CheckPeriod time.Duration `env:"CHECK_PERIOD,default=5s" desc:"Период проверки отставания реплик"`
+		}
+		SlowQueryThreshold time.Duration `env:"SLOW_QUERY_THRESHOLD,default=500ms" desc:"Запросы дольше порога логируются как медленные"`
 	} `env:"DATABASE"`
 
+	Queue struct {
+		Concurrency  int           `env:"CONCURRENCY,default=10,min=1" desc:"Число параллельных обработчиков задач"`
+		PollInterval time.Duration `env:"POLL_INTERVAL,default=1s" desc:"Интервал опроса очереди"`
+		JobTimeout   time.Duration `env:"JOB_TIMEOUT,default=5m" desc:"Таймаут выполнения задачи"`
+	} `env:"QUEUE"`
+
+	Outbox struct {
+		Publisher    string        `env:"PUBLISHER,default=log,oneof=log webhook file" desc:"Доставка событий outbox: log / webhook / file"`
+		WebhookURL   string        `env:"WEBHOOK_URL,secret,url" desc:"URL для publisher=webhook"`
+		File         string        `env:"FILE,default=outbox.jsonl" desc:"Файл для publisher=file"`
+		PollInterval time.Duration `env:"POLL_INTERVAL,default=1s" desc:"Интервал опроса outbox"`
+		BatchSize    int           `env:"BATCH_SIZE,default=100,min=1" desc:"Число событий за один проход"`
+	} `env:"OUTBOX"`
+
+	Leader struct {
+		RetryPeriod time.Duration `env:"RETRY_PERIOD,default=5s" desc:"Как часто экземпляр пытается стать лидером"`
+		CheckPeriod time.Duration `env:"CHECK_PERIOD,default=2s" desc:"Как часто лидер проверяет, что лидерство не потеряно"`
+	} `env:"LEADER"`
+
+	Scheduler struct {
+		JobRetention    time.Duration `env:"JOB_RETENTION,default=168h" desc:"Срок хранения завершённых задач очереди"`
+		OutboxRetention time.Duration `env:"OUTBOX_RETENTION,default=168h" desc:"Срок хранения доставленных событий outbox"`
+	} `env:"SCHEDULER"`
+
 	Log struct {
-		Level      string `env:"LEVEL,default=debug"`
-		Format     string `env:"FORMAT,default=text"`
-		TimeFormat string `env:"TIME_FORMAT,default=2006-01-02T15:04:05Z07:00"`
+		Level      string `env:"LEVEL,default=debug,oneof=debug info warn error" desc:"Уровень логов: debug / info / warn / error"`
+		Format     string `env:"FORMAT,default=text,oneof=text json" desc:"Формат логов: text / json"`
+		TimeFormat string `env:"TIME_FORMAT,default=2006-01-02T15:04:05Z07:00" desc:"Формат времени в логах"`
 	} `env:"LOG"`
 }
 
+// Validate проверяет связи между полями, которые не выражаются тегами env.
+func (cfg *Config) Validate() error {
+	var errs []error
+	if cfg.Database.Pool.MinConns > cfg.Database.Pool.MaxConns {
+		errs = append(errs, fmt.Errorf("DATABASE_POOL_MIN_CONNS (%d) cannot exceed DATABASE_POOL_MAX_CONNS (%d)",
+			cfg.Database.Pool.MinConns, cfg.Database.Pool.MaxConns))
+	}
+	if cfg.Outbox.Publisher == "webhook" && cfg.Outbox.WebhookURL == "" {
+		errs = append(errs, errors.New("OUTBOX_WEBHOOK_URL is required for OUTBOX_PUBLISHER=webhook"))
+	}
+	return errors.Join(errs...)
+}
+
 // DSN строит строку подключения к PostgreSQL. SSL-режим передаётся отдельно через WithSSLMode.
 func (cfg Config) DSN() string {
-	return fmt.Sprintf("postgres://%s:%s@%s:%d/%s?application_name=%s",
+	return cfg.dsn(net.JoinHostPort(cfg.Database.Host, strconv.Itoa(cfg.Database.Port)))
+}
+
+// ReplicaDSNs строит строки подключения к репликам из DATABASE_REPLICAS_HOSTS.
+// Хост без порта использует DATABASE_PORT, учётные данные совпадают с основной базой.
+func (cfg Config) ReplicaDSNs() []string {
+	dsns := make([]string, 0, len(cfg.Database.Replicas.Hosts))
+	for _, host := range cfg.Database.Replicas.Hosts {
+		if _, _, err := net.SplitHostPort(host); err != nil {
+			host = net.JoinHostPort(host, strconv.Itoa(cfg.Database.Port))
+		}
+		dsns = append(dsns, cfg.dsn(host))
+	}
+	return dsns
+}
+
+func (cfg Config) dsn(hostPort string) string {
+	return fmt.Sprintf("postgres://%s:%s@%s/%s?application_name=%s",
 		cfg.Database.User.Name,
-		cfg.Database.User.Password,
-		cfg.Database.Host,
-		cfg.Database.Port,
+		cfg.Database.User.Password.Value(),
+		hostPort,
 		cfg.Database.Name,
 		cfg.App.Name,
 	)
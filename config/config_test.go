@@ -0,0 +1,196 @@
+package config_test
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/desulaidovich/app/config"
+	"github.com/desulaidovich/app/internal/postgres"
+	"github.com/desulaidovich/app/pkg/env"
+	"github.com/desulaidovich/app/pkg/log"
+)
+
+// oneof возвращает значения опции oneof из тега env поля по пути из имён полей.
+func oneof(t *testing.T, path ...string) []string {
+	t.Helper()
+
+	f, ok := reflect.TypeFor[config.Config]().FieldByName(path[0])
+	for _, name := range path[1:] {
+		if !ok {
+			break
+		}
+		f, ok = f.Type.FieldByName(name)
+	}
+	if !ok {
+		t.Fatalf("field %s not found", strings.Join(path, "."))
+	}
+
+	for opt := range strings.SplitSeq(f.Tag.Get("env"), ",") {
+		if values, ok := strings.CutPrefix(opt, "oneof="); ok {
+			return strings.Fields(values)
+		}
+	}
+	t.Fatalf("field %s has no oneof option", strings.Join(path, "."))
+	return nil
+}
+
+func TestOneofMatchesConsumers(t *testing.T) {
+	tests := []struct {
+		name   string
+		path   []string
+		want   []string
+		accept func(value string) error
+	}{
+		{
+			name: "DATABASE_SSL_MODE",
+			path: []string{"Database", "SSLMode"},
+			want: postgres.SSLModes,
+			accept: func(value string) error {
+				return postgres.WithSSLMode(value)(&postgres.Config{})
+			},
+		},
+		{
+			name: "LOG_LEVEL",
+			path: []string{"Log", "Level"},
+			accept: func(value string) error {
+				_, err := log.New(log.WithOutput(io.Discard), log.WithLevel(value))
+				return err
+			},
+		},
+		{
+			name: "LOG_FORMAT",
+			path: []string{"Log", "Format"},
+			want: []string{log.OutputText, log.OutputJSON},
+			accept: func(value string) error {
+				_, err := log.New(log.WithOutput(io.Discard), log.WithFormat(value))
+				return err
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			values := oneof(t, tt.path...)
+			if tt.want != nil && !slices.Equal(values, tt.want) {
+				t.Errorf("oneof = %v, want %v", values, tt.want)
+			}
+			for _, v := range values {
+				if err := tt.accept(v); err != nil {
+					t.Errorf("%q passes validation but is rejected: %v", v, err)
+				}
+			}
+		})
+	}
+}
+
+func TestPorts(t *testing.T) {
+	tests := []struct {
+		name     string
+		lines    []string
+		wantHTTP int
+		wantGRPC int
+		wantErr  string
+	}{
+		{name: "defaults", wantHTTP: 8080, wantGRPC: 9090},
+		{name: "custom", lines: []string{"HTTP_PORT=8081", "GRPC_PORT=9091"}, wantHTTP: 8081, wantGRPC: 9091},
+		{name: "out of range", lines: []string{"HTTP_PORT=99999"}, wantErr: "field HTTP_PORT: must be at most 65535"},
+		{name: "zero", lines: []string{"GRPC_PORT=0"}, wantErr: "field GRPC_PORT: must be at least 1"},
+		{name: "not a number", lines: []string{"HTTP_PORT=http"}, wantErr: "field HTTP_PORT: invalid int"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			file := filepath.Join(t.TempDir(), ".env")
+			if err := os.WriteFile(file, []byte(strings.Join(tt.lines, "\n")), 0o600); err != nil {
+				t.Fatal(err)
+			}
+
+			var ports config.Ports
+			err := env.LoadFile(&ports, file)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if ports.HTTP.Port != tt.wantHTTP || ports.GRPC.Port != tt.wantGRPC {
+				t.Errorf("ports = %d/%d, want %d/%d", ports.HTTP.Port, ports.GRPC.Port, tt.wantHTTP, tt.wantGRPC)
+			}
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		modify  func(cfg *config.Config)
+		wantErr []string
+	}{
+		{name: "valid", modify: func(*config.Config) {}},
+		{
+			name: "min conns above max conns",
+			modify: func(cfg *config.Config) {
+				cfg.Database.Pool.MinConns, cfg.Database.Pool.MaxConns = 10, 5
+			},
+			wantErr: []string{"DATABASE_POOL_MIN_CONNS (10) cannot exceed DATABASE_POOL_MAX_CONNS (5)"},
+		},
+		{
+			name: "min conns equal to max conns",
+			modify: func(cfg *config.Config) {
+				cfg.Database.Pool.MinConns, cfg.Database.Pool.MaxConns = 5, 5
+			},
+		},
+		{
+			name:    "webhook without URL",
+			modify:  func(cfg *config.Config) { cfg.Outbox.Publisher = "webhook" },
+			wantErr: []string{"OUTBOX_WEBHOOK_URL is required for OUTBOX_PUBLISHER=webhook"},
+		},
+		{
+			name: "webhook with URL",
+			modify: func(cfg *config.Config) {
+				cfg.Outbox.Publisher, cfg.Outbox.WebhookURL = "webhook", "https://example.com/events"
+			},
+		},
+		{
+			name: "all errors are reported",
+			modify: func(cfg *config.Config) {
+				cfg.Database.Pool.MinConns = 100
+				cfg.Outbox.Publisher = "webhook"
+			},
+			wantErr: []string{"DATABASE_POOL_MIN_CONNS", "OUTBOX_WEBHOOK_URL"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var cfg config.Config
+			cfg.Database.Pool.MinConns, cfg.Database.Pool.MaxConns = 5, 25
+			cfg.Outbox.Publisher = "log"
+			tt.modify(&cfg)
+
+			err := cfg.Validate()
+			if len(tt.wantErr) == 0 {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatalf("error = nil, want %q", tt.wantErr)
+			}
+			for _, want := range tt.wantErr {
+				if !strings.Contains(err.Error(), want) {
+					t.Errorf("error = %v, want %q", err, want)
+				}
+			}
+		})
+	}
+}
@@ -6,30 +6,34 @@ import (
 	"fmt"
 	"net"
 	"net/http"
+	"slices"
+	"strconv"
+	"sync"
 	"time"
 
 	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/reflection"
 
-	healthv1 "github.com/desulaidovich/app/api/health/v1"
 	"github.com/desulaidovich/app/config"
 	"github.com/desulaidovich/app/internal/grpcserver"
-	"github.com/desulaidovich/app/internal/handler"
 	"github.com/desulaidovich/app/internal/middleware"
-	"github.com/desulaidovich/app/internal/postgres"
 	"github.com/desulaidovich/app/pkg/log"
 )
 
 type App struct {
-	cfg     *config.Config
-	log     log.Logger
-	db      *postgres.Pool
-	grpcSrv *grpcserver.Server
-	httpSrv *http.Server
-	name    string
-	version string
-	build   string
+	cfg      *config.Config
+	log      log.Logger
+	services []Service
+	grpcSrv  *grpcserver.Server
+	httpSrv  *http.Server
+	name     string
+	version  string
+	build    string
+
+	mu sync.Mutex
+	// started — сервисы, чей Starter отработал; Stopper вызывается только у них.
+	started []Service
 }
 
 type Option func(*App) error
@@ -78,16 +82,6 @@ func WithLogger(logger log.Logger) Option {
 	}
 }
 
-func WithPostgres(db *postgres.Pool) Option {
-	return func(a *App) error {
-		if db == nil {
-			return errors.New("postgres pool cannot be nil")
-		}
-		a.db = db
-		return nil
-	}
-}
-
 func New(opts ...Option) (*App, error) {
 	app := new(App)
 
@@ -103,15 +97,14 @@ func New(opts ...Option) (*App, error) {
 	if app.log == nil {
 		return nil, errors.New("logger is required")
 	}
-	if app.db == nil {
-		return nil, errors.New("postgres is required")
-	}
 
 	app.grpcSrv = grpcserver.New(
-		net.JoinHostPort("", app.cfg.GRPC.Port),
+		net.JoinHostPort("", strconv.Itoa(app.cfg.GRPC.Port)),
 		grpc.ChainUnaryInterceptor(
 			middleware.GRPCRecovery(app.log),
+			middleware.GRPCRequestID(),
 			middleware.GRPCLogging(app.log),
+			middleware.GRPCErrors(),
 		),
 	)
 
@@ -119,17 +112,23 @@ func New(opts ...Option) (*App, error) {
 		reflection.Register(app.grpcSrv.Server())
 	}
 
-	healthHandler := handler.NewHealthHandler(app.version, app.build)
-	healthv1.RegisterHealthServiceServer(app.grpcSrv.Server(), healthHandler)
+	enabled, err := app.enabledServices()
+	if err != nil {
+		return nil, err
+	}
+	app.services = enabled
 
-	gwMux := runtime.NewServeMux()
-	if err := healthv1.RegisterHealthServiceHandlerServer(context.Background(), gwMux, healthHandler); err != nil {
-		return nil, fmt.Errorf("failed to register health service handler: %w", err)
+	gwMux := runtime.NewServeMux(runtime.WithErrorHandler(middleware.GatewayErrors))
+	for _, s := range app.services {
+		s.RegisterGRPC(app.grpcSrv.Server())
+		if err := s.RegisterGateway(context.Background(), gwMux); err != nil {
+			return nil, fmt.Errorf("failed to register %s service handler: %w", s.Name(), err)
+		}
 	}
 
 	app.httpSrv = &http.Server{
-		Addr:              net.JoinHostPort("", app.cfg.HTTP.Port),
-		Handler:           middleware.Chain(gwMux, middleware.Logging(app.log), middleware.CORS),
+		Addr:              net.JoinHostPort("", strconv.Itoa(app.cfg.HTTP.Port)),
+		Handler:           middleware.Chain(gwMux, middleware.RequestID, middleware.Logging(app.log), middleware.CORS),
 		ReadHeaderTimeout: 5 * time.Second,
 		ReadTimeout:       15 * time.Second,
 		WriteTimeout:      30 * time.Second,
@@ -140,7 +139,22 @@ func New(opts ...Option) (*App, error) {
 }
 
 func (app *App) Start(ctx context.Context) error {
+	names := make([]string, 0, len(app.services))
+	for _, s := range app.services {
+		if starter, ok := s.(Starter); ok {
+			if err := starter.Start(ctx); err != nil {
+				err = fmt.Errorf("failed to start %s service: %w", s.Name(), err)
+				return errors.Join(err, app.stopServices(context.WithoutCancel(ctx)))
+			}
+		}
+		app.mu.Lock()
+		app.started = append(app.started, s)
+		app.mu.Unlock()
+		names = append(names, s.Name())
+	}
+
 	app.log.With(map[string]any{
+		"services":  names,
 		"name":      app.cfg.App.Name,
 		"mode":      app.cfg.App.Env,
 		"version":   app.version,
@@ -171,16 +185,37 @@ func (app *App) Start(ctx context.Context) error {
 	}
 }
 
+// Stop останавливает серверы и вызывает Stopper запущенных сервисов, даже если
+// остановить серверы не удалось.
 func (app *App) Stop(ctx context.Context) error {
 	app.log.Info("Application stopping")
 
+	var errs []error
 	if err := app.grpcSrv.Stop(ctx); err != nil {
-		return fmt.Errorf("could not stop grpc server: %w", err)
+		errs = append(errs, fmt.Errorf("could not stop grpc server: %w", err))
 	}
-
 	if err := app.httpSrv.Shutdown(ctx); err != nil {
-		return fmt.Errorf("could not stop http server: %w", err)
+		errs = append(errs, fmt.Errorf("could not stop http server: %w", err))
 	}
+	errs = append(errs, app.stopServices(ctx))
+	return errors.Join(errs...)
+}
 
-	return nil
+// stopServices вызывает Stopper запущенных сервисов в обратном порядке. Каждый сервис
+// останавливается один раз, даже если Stop вызван после неудачного Start.
+func (app *App) stopServices(ctx context.Context) error {
+	app.mu.Lock()
+	started := app.started
+	app.started = nil
+	app.mu.Unlock()
+
+	var errs []error
+	for _, s := range slices.Backward(started) {
+		if stopper, ok := s.(Stopper); ok {
+			if err := stopper.Stop(ctx); err != nil {
+				errs = append(errs, fmt.Errorf("could not stop %s service: %w", s.Name(), err))
+			}
+		}
+	}
+	return errors.Join(errs...)
 }
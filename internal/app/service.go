@@ -0,0 +1,129 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"google.golang.org/grpc"
+)
+
+// Service — модуль API, подключаемый к приложению через WithServices.
+type Service interface {
+	// Name — короткое имя сервиса, по нему сервис отключается в APP_DISABLED_SERVICES.
+	Name() string
+	RegisterGRPC(s *grpc.Server)
+	RegisterGateway(ctx context.Context, mux *runtime.ServeMux) error
+}
+
+// Starter — необязательный хук, который вызывается при старте приложения до запуска серверов.
+// Start не должен блокироваться.
+type Starter interface {
+	Start(ctx context.Context) error
+}
+
+// Stopper — необязательный хук, который вызывается после остановки серверов.
+type Stopper interface {
+	Stop(ctx context.Context) error
+}
+
+// Dependent — необязательный интерфейс сервиса, которому нужны другие сервисы.
+// Зависимости должны быть включены и стартуют раньше зависимого сервиса.
+type Dependent interface {
+	DependsOn() []string
+}
+
+// WithServices добавляет API-сервисы. Сервисы из APP_DISABLED_SERVICES не регистрируются,
+// а имя в APP_DISABLED_SERVICES, которому не соответствует ни один сервис, — ошибка New.
+func WithServices(services ...Service) Option {
+	return func(a *App) error {
+		for _, s := range services {
+			if s == nil {
+				return errors.New("service cannot be nil")
+			}
+			a.services = append(a.services, s)
+		}
+		return nil
+	}
+}
+
+// enabledServices отбрасывает отключённые конфигурацией сервисы и упорядочивает остальные
+// так, чтобы зависимости шли раньше зависимых.
+func (app *App) enabledServices() ([]Service, error) {
+	var (
+		seen   = make(map[string]bool, len(app.services))
+		byName = make(map[string]Service, len(app.services))
+		names  []string
+	)
+	for _, s := range app.services {
+		name := s.Name()
+		// Дубликаты проверяются до отключения: иначе два сервиса с отключённым именем прошли бы молча.
+		if seen[name] {
+			return nil, fmt.Errorf("duplicate service: %s", name)
+		}
+		seen[name] = true
+		if slices.Contains(app.cfg.App.DisabledServices, name) {
+			app.log.With(map[string]any{"service": name}).Info("Service disabled")
+			continue
+		}
+		byName[name] = s
+		names = append(names, name)
+	}
+
+	// Опечатка в имени оставила бы сервис включённым, поэтому неизвестные имена — ошибка.
+	var unknown []string
+	for _, name := range app.cfg.App.DisabledServices {
+		if !seen[name] {
+			unknown = append(unknown, name)
+		}
+	}
+	if len(unknown) > 0 {
+		return nil, fmt.Errorf("unknown services in APP_DISABLED_SERVICES: %s (registered: %s)",
+			strings.Join(unknown, ", "), strings.Join(slices.Sorted(maps.Keys(seen)), ", "))
+	}
+
+	const (
+		visiting = 1
+		visited  = 2
+	)
+	var (
+		state   = make(map[string]int, len(names))
+		ordered = make([]Service, 0, len(names))
+		visit   func(name string) error
+	)
+	visit = func(name string) error {
+		switch state[name] {
+		case visited:
+			return nil
+		case visiting:
+			return fmt.Errorf("service dependency cycle at %s", name)
+		}
+		state[name] = visiting
+
+		if d, ok := byName[name].(Dependent); ok {
+			for _, dep := range d.DependsOn() {
+				if _, ok := byName[dep]; !ok {
+					return fmt.Errorf("service %s depends on %s, which is not enabled", name, dep)
+				}
+				if err := visit(dep); err != nil {
+					return err
+				}
+			}
+		}
+
+		state[name] = visited
+		ordered = append(ordered, byName[name])
+		return nil
+	}
+
+	for _, name := range names {
+		if err := visit(name); err != nil {
+			return nil, err
+		}
+	}
+	return ordered, nil
+}
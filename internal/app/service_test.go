@@ -0,0 +1,124 @@
+package app
+
+import (
+	"context"
+	"io"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"google.golang.org/grpc"
+
+	"github.com/desulaidovich/app/config"
+	"github.com/desulaidovich/app/pkg/log"
+)
+
+type fakeService struct {
+	name string
+	deps []string
+}
+
+func (s *fakeService) Name() string                                             { return s.name }
+func (s *fakeService) RegisterGRPC(*grpc.Server)                                {}
+func (s *fakeService) RegisterGateway(context.Context, *runtime.ServeMux) error { return nil }
+func (s *fakeService) DependsOn() []string                                      { return s.deps }
+
+func TestEnabledServices(t *testing.T) {
+	tests := []struct {
+		name     string
+		services []*fakeService
+		disabled []string
+		want     []string
+		wantErr  string
+	}{
+		{
+			name:     "keeps registration order without dependencies",
+			services: []*fakeService{{name: "health"}, {name: "jobs"}, {name: "scheduler"}},
+			want:     []string{"health", "jobs", "scheduler"},
+		},
+		{
+			name:     "dependencies come first",
+			services: []*fakeService{{name: "orders", deps: []string{"users", "jobs"}}, {name: "users"}, {name: "jobs", deps: []string{"users"}}},
+			want:     []string{"users", "jobs", "orders"},
+		},
+		{
+			name:     "disabled service is dropped",
+			services: []*fakeService{{name: "health"}, {name: "jobs"}, {name: "scheduler"}},
+			disabled: []string{"jobs", "scheduler"},
+			want:     []string{"health"},
+		},
+		{
+			name:     "unknown disabled service",
+			services: []*fakeService{{name: "health"}, {name: "jobs"}},
+			disabled: []string{"job", "health", "admin"},
+			wantErr:  "unknown services in APP_DISABLED_SERVICES: job, admin (registered: health, jobs)",
+		},
+		{
+			name:     "duplicate",
+			services: []*fakeService{{name: "jobs"}, {name: "jobs"}},
+			wantErr:  "duplicate service: jobs",
+		},
+		{
+			name:     "disabled duplicate",
+			services: []*fakeService{{name: "jobs"}, {name: "jobs"}},
+			disabled: []string{"jobs"},
+			wantErr:  "duplicate service: jobs",
+		},
+		{
+			name:     "dependency on a disabled service",
+			services: []*fakeService{{name: "scheduler", deps: []string{"jobs"}}, {name: "jobs"}},
+			disabled: []string{"jobs"},
+			wantErr:  "service scheduler depends on jobs, which is not enabled",
+		},
+		{
+			name:     "dependency on a missing service",
+			services: []*fakeService{{name: "scheduler", deps: []string{"jobs"}}},
+			wantErr:  "service scheduler depends on jobs, which is not enabled",
+		},
+		{
+			name:     "cycle",
+			services: []*fakeService{{name: "a", deps: []string{"b"}}, {name: "b", deps: []string{"c"}}, {name: "c", deps: []string{"a"}}},
+			wantErr:  "service dependency cycle at a",
+		},
+		{
+			name:     "self dependency",
+			services: []*fakeService{{name: "a", deps: []string{"a"}}},
+			wantErr:  "service dependency cycle at a",
+		},
+	}
+
+	logger, err := log.New(log.WithOutput(io.Discard))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := &App{cfg: &config.Config{}, log: logger}
+			app.cfg.App.DisabledServices = tt.disabled
+			for _, s := range tt.services {
+				app.services = append(app.services, s)
+			}
+
+			got, err := app.enabledServices()
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			names := make([]string, 0, len(got))
+			for _, s := range got {
+				names = append(names, s.Name())
+			}
+			if !slices.Equal(names, tt.want) {
+				t.Errorf("enabledServices = %v, want %v", names, tt.want)
+			}
+		})
+	}
+}
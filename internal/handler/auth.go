@@ -0,0 +1,31 @@
+package handler
+
+import (
+	"context"
+	"crypto/subtle"
+	"strings"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// adminAuth проверяет токен административных RPC в заголовке Authorization: Bearer <token>.
+// Проверка работает для gRPC и grpc-gateway: шлюз передаёт заголовок в метаданных authorization.
+// Пустой токен отключает административные RPC.
+type adminAuth string
+
+func (token adminAuth) check(ctx context.Context) error {
+	if token == "" {
+		return status.Error(codes.PermissionDenied, "admin API is disabled, set APP_ADMIN_TOKEN to enable it")
+	}
+
+	md, _ := metadata.FromIncomingContext(ctx)
+	for _, value := range md.Get("authorization") {
+		got, ok := strings.CutPrefix(value, "Bearer ")
+		if ok && subtle.ConstantTimeCompare([]byte(got), []byte(token)) == 1 {
+			return nil
+		}
+	}
+	return status.Error(codes.Unauthenticated, "missing or invalid admin token")
+}
@@ -3,22 +3,46 @@ package handler
 import (
 	"context"
 
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
 	healthv1 "github.com/desulaidovich/app/api/health/v1"
 )
 
+// Pinger проверяет доступность зависимости, например, пула соединений с базой.
+type Pinger interface {
+	Ping(ctx context.Context) error
+}
+
 type HealthHandler struct {
 	healthv1.UnimplementedHealthServiceServer
 	version string
 	build   string
+	db      Pinger
 }
 
-func NewHealthHandler(version, build string) *HealthHandler {
+func NewHealthHandler(version, build string, db Pinger) *HealthHandler {
 	return &HealthHandler{
 		version: version,
 		build:   build,
+		db:      db,
 	}
 }
 
+func (h *HealthHandler) Name() string {
+	return "health"
+}
+
+func (h *HealthHandler) RegisterGRPC(s *grpc.Server) {
+	healthv1.RegisterHealthServiceServer(s, h)
+}
+
+func (h *HealthHandler) RegisterGateway(ctx context.Context, mux *runtime.ServeMux) error {
+	return healthv1.RegisterHealthServiceHandlerServer(ctx, mux, h)
+}
+
 func (h *HealthHandler) Health(_ context.Context, _ *healthv1.HealthRequest) (*healthv1.HealthResponse, error) {
 	return &healthv1.HealthResponse{
 		Status:  "ok",
@@ -26,3 +50,11 @@ func (h *HealthHandler) Health(_ context.Context, _ *healthv1.HealthRequest) (*h
 		Build:   h.build,
 	}, nil
 }
+
+// Ready возвращает UNAVAILABLE (HTTP 503), если база данных недоступна.
+func (h *HealthHandler) Ready(ctx context.Context, _ *healthv1.ReadyRequest) (*healthv1.ReadyResponse, error) {
+	if err := h.db.Ping(ctx); err != nil {
+		return nil, status.Errorf(codes.Unavailable, "database is unavailable: %v", err)
+	}
+	return &healthv1.ReadyResponse{Status: "ok"}, nil
+}
@@ -0,0 +1,124 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	jobsv1 "github.com/desulaidovich/app/api/jobs/v1"
+	"github.com/desulaidovich/app/internal/queue"
+)
+
+const maxJobsPageSize = 500
+
+// JobsHandler — административный API очереди: все RPC требуют adminToken.
+type JobsHandler struct {
+	jobsv1.UnimplementedJobServiceServer
+	queue *queue.Queue
+	auth  adminAuth
+}
+
+func NewJobsHandler(q *queue.Queue, adminToken string) *JobsHandler {
+	return &JobsHandler{queue: q, auth: adminAuth(adminToken)}
+}
+
+func (h *JobsHandler) Name() string {
+	return "jobs"
+}
+
+func (h *JobsHandler) RegisterGRPC(s *grpc.Server) {
+	jobsv1.RegisterJobServiceServer(s, h)
+}
+
+func (h *JobsHandler) RegisterGateway(ctx context.Context, mux *runtime.ServeMux) error {
+	return jobsv1.RegisterJobServiceHandlerServer(ctx, mux, h)
+}
+
+func (h *JobsHandler) ListJobs(ctx context.Context, req *jobsv1.ListJobsRequest) (*jobsv1.ListJobsResponse, error) {
+	if err := h.auth.check(ctx); err != nil {
+		return nil, err
+	}
+	if req.GetPageSize() < 0 || req.GetPageSize() > maxJobsPageSize {
+		return nil, status.Errorf(codes.InvalidArgument, "page_size must be between 0 and %d", maxJobsPageSize)
+	}
+
+	jobs, err := h.queue.List(ctx, queue.ListFilter{
+		Queue:   req.GetQueue(),
+		Kind:    req.GetKind(),
+		Status:  req.GetStatus(),
+		Limit:   int(req.GetPageSize()),
+		AfterID: req.GetAfterId(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &jobsv1.ListJobsResponse{Jobs: make([]*jobsv1.Job, 0, len(jobs))}
+	for _, job := range jobs {
+		resp.Jobs = append(resp.Jobs, jobToProto(job))
+	}
+	if len(jobs) > 0 {
+		resp.NextAfterId = jobs[len(jobs)-1].ID
+	}
+	return resp, nil
+}
+
+func (h *JobsHandler) RetryJob(ctx context.Context, req *jobsv1.RetryJobRequest) (*jobsv1.Job, error) {
+	if err := h.auth.check(ctx); err != nil {
+		return nil, err
+	}
+	job, err := h.queue.Retry(ctx, req.GetId())
+	if err != nil {
+		return nil, jobError(err)
+	}
+	return jobToProto(job), nil
+}
+
+func (h *JobsHandler) CancelJob(ctx context.Context, req *jobsv1.CancelJobRequest) (*jobsv1.Job, error) {
+	if err := h.auth.check(ctx); err != nil {
+		return nil, err
+	}
+	job, err := h.queue.Cancel(ctx, req.GetId())
+	if err != nil {
+		return nil, jobError(err)
+	}
+	return jobToProto(job), nil
+}
+
+func jobError(err error) error {
+	if errors.Is(err, queue.ErrInvalidState) {
+		return status.Error(codes.FailedPrecondition, err.Error())
+	}
+	return err
+}
+
+func jobToProto(job *queue.Job) *jobsv1.Job {
+	return &jobsv1.Job{
+		Id:          job.ID,
+		Queue:       job.Queue,
+		Kind:        job.Kind,
+		Payload:     string(job.Payload),
+		Status:      job.Status,
+		Attempts:    job.Attempts,
+		MaxAttempts: job.MaxAttempts,
+		UniqueKey:   job.UniqueKey,
+		LastError:   job.LastError,
+		RunAt:       timestamppb.New(job.RunAt),
+		CreatedAt:   timestamppb.New(job.CreatedAt),
+		UpdatedAt:   timestamppb.New(job.UpdatedAt),
+		FinishedAt:  optionalTimestamp(job.FinishedAt),
+	}
+}
+
+func optionalTimestamp(t *time.Time) *timestamppb.Timestamp {
+	if t == nil {
+		return nil
+	}
+	return timestamppb.New(*t)
+}
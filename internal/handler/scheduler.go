@@ -0,0 +1,99 @@
+package handler
+
+import (
+	"context"
+
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	schedulerv1 "github.com/desulaidovich/app/api/scheduler/v1"
+	"github.com/desulaidovich/app/internal/scheduler"
+)
+
+// SchedulerHandler — административный API планировщика: все RPC требуют adminToken.
+type SchedulerHandler struct {
+	schedulerv1.UnimplementedSchedulerServiceServer
+	scheduler *scheduler.Scheduler
+	auth      adminAuth
+}
+
+func NewSchedulerHandler(s *scheduler.Scheduler, adminToken string) *SchedulerHandler {
+	return &SchedulerHandler{scheduler: s, auth: adminAuth(adminToken)}
+}
+
+func (h *SchedulerHandler) Name() string {
+	return "scheduler"
+}
+
+func (h *SchedulerHandler) RegisterGRPC(s *grpc.Server) {
+	schedulerv1.RegisterSchedulerServiceServer(s, h)
+}
+
+func (h *SchedulerHandler) RegisterGateway(ctx context.Context, mux *runtime.ServeMux) error {
+	return schedulerv1.RegisterSchedulerServiceHandlerServer(ctx, mux, h)
+}
+
+func (h *SchedulerHandler) ListTasks(ctx context.Context, _ *schedulerv1.ListTasksRequest) (*schedulerv1.ListTasksResponse, error) {
+	if err := h.auth.check(ctx); err != nil {
+		return nil, err
+	}
+	tasks, err := h.scheduler.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &schedulerv1.ListTasksResponse{Tasks: make([]*schedulerv1.Task, 0, len(tasks))}
+	for _, task := range tasks {
+		resp.Tasks = append(resp.Tasks, taskToProto(task))
+	}
+	return resp, nil
+}
+
+func (h *SchedulerHandler) RunTask(ctx context.Context, req *schedulerv1.RunTaskRequest) (*schedulerv1.Task, error) {
+	return h.update(ctx, req.GetName(), h.scheduler.RunNow)
+}
+
+func (h *SchedulerHandler) PauseTask(ctx context.Context, req *schedulerv1.PauseTaskRequest) (*schedulerv1.Task, error) {
+	return h.update(ctx, req.GetName(), h.scheduler.Pause)
+}
+
+func (h *SchedulerHandler) ResumeTask(ctx context.Context, req *schedulerv1.ResumeTaskRequest) (*schedulerv1.Task, error) {
+	return h.update(ctx, req.GetName(), h.scheduler.Resume)
+}
+
+func (h *SchedulerHandler) update(
+	ctx context.Context,
+	name string,
+	fn func(context.Context, string) (*scheduler.Task, error),
+) (*schedulerv1.Task, error) {
+	if err := h.auth.check(ctx); err != nil {
+		return nil, err
+	}
+	if name == "" {
+		return nil, status.Error(codes.InvalidArgument, "name is required")
+	}
+
+	task, err := fn(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	return taskToProto(task), nil
+}
+
+func taskToProto(task *scheduler.Task) *schedulerv1.Task {
+	return &schedulerv1.Task{
+		Name:           task.Name,
+		Schedule:       task.Schedule,
+		LeaderOnly:     task.LeaderOnly,
+		Paused:         task.Paused,
+		RunRequested:   task.RunRequested,
+		LastStartedAt:  optionalTimestamp(task.LastStartedAt),
+		LastFinishedAt: optionalTimestamp(task.LastFinishedAt),
+		LastStatus:     task.LastStatus,
+		LastError:      task.LastError,
+		LastDurationMs: task.LastDuration.Milliseconds(),
+		NextRunAt:      optionalTimestamp(task.NextRunAt),
+	}
+}
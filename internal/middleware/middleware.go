@@ -2,17 +2,31 @@ package middleware
 
 import (
 	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
 	"net/http"
 	"runtime/debug"
 	"time"
 
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/status"
 
+	"github.com/desulaidovich/app/internal/postgres"
 	"github.com/desulaidovich/app/pkg/log"
 )
 
+const (
+	RequestIDHeader   = "X-Request-Id"
+	requestIDMetadata = "x-request-id"
+	// maxRequestIDLen ограничивает длину идентификатора от клиента: он попадает в логи и заголовки.
+	maxRequestIDLen = 128
+)
+
 type statusWriter struct {
 	http.ResponseWriter
 	status int
@@ -30,21 +44,37 @@ func Logging(logger log.Logger) func(http.Handler) http.Handler {
 			sw := &statusWriter{ResponseWriter: w, status: http.StatusOK}
 			next.ServeHTTP(sw, r)
 			logger.With(map[string]any{
-				"method":   r.Method,
-				"path":     r.URL.Path,
-				"status":   sw.status,
-				"duration": time.Since(start).String(),
-				"ip":       r.RemoteAddr,
+				"method":     r.Method,
+				"path":       r.URL.Path,
+				"status":     sw.status,
+				"duration":   time.Since(start).String(),
+				"ip":         r.RemoteAddr,
+				"request_id": log.RequestID(r.Context()),
 			}).Info("HTTP request")
 		})
 	}
 }
 
+// RequestID берёт идентификатор запроса из заголовка X-Request-Id, кладёт его в контекст
+// и возвращает клиенту в том же заголовке. Если заголовка нет или он слишком длинный
+// либо содержит недопустимые символы, генерируется новый идентификатор.
+func RequestID(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get(RequestIDHeader)
+		if !validRequestID(id) {
+			id = newRequestID()
+		}
+		w.Header().Set(RequestIDHeader, id)
+		next.ServeHTTP(w, r.WithContext(log.ContextWithRequestID(r.Context(), id)))
+	})
+}
+
 func CORS(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Access-Control-Allow-Origin", "*")
 		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, PATCH, DELETE, OPTIONS")
-		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
+		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization, "+RequestIDHeader)
+		w.Header().Set("Access-Control-Expose-Headers", RequestIDHeader)
 		if r.Method == http.MethodOptions {
 			w.WriteHeader(http.StatusNoContent)
 			return
@@ -76,6 +106,23 @@ func GRPCRecovery(logger log.Logger) grpc.UnaryServerInterceptor {
 	}
 }
 
+// GRPCRequestID — аналог RequestID для gRPC: идентификатор берётся из метаданных x-request-id.
+func GRPCRequestID() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		var id string
+		if md, ok := metadata.FromIncomingContext(ctx); ok {
+			if values := md.Get(requestIDMetadata); len(values) > 0 {
+				id = values[0]
+			}
+		}
+		if !validRequestID(id) {
+			id = newRequestID()
+		}
+		_ = grpc.SetHeader(ctx, metadata.Pairs(requestIDMetadata, id))
+		return handler(log.ContextWithRequestID(ctx, id), req)
+	}
+}
+
 func GRPCLogging(logger log.Logger) grpc.UnaryServerInterceptor {
 	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
 		start := time.Now()
@@ -85,10 +132,86 @@ func GRPCLogging(logger log.Logger) grpc.UnaryServerInterceptor {
 			code = s.Code()
 		}
 		logger.With(map[string]any{
-			"method":   info.FullMethod,
-			"code":     code.String(),
-			"duration": time.Since(start).String(),
+			"method":     info.FullMethod,
+			"code":       code.String(),
+			"duration":   time.Since(start).String(),
+			"request_id": log.RequestID(ctx),
 		}).Info("gRPC request")
 		return resp, err
 	}
 }
+
+// GRPCErrors переводит ошибки PostgreSQL, возвращённые обработчиком, в статусы gRPC.
+// Ошибки, уже являющиеся статусом gRPC, передаются без изменений.
+func GRPCErrors() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		resp, err := handler(ctx, req)
+		if err == nil {
+			return resp, nil
+		}
+		if _, ok := status.FromError(err); ok {
+			return resp, err
+		}
+		return resp, postgresStatus(err)
+	}
+}
+
+// GatewayErrors — обработчик ошибок grpc-gateway с той же трансляцией, что и GRPCErrors.
+// Нужен, потому что HTTP-запросы вызывают обработчики напрямую, минуя интерсепторы gRPC.
+func GatewayErrors(ctx context.Context, mux *runtime.ServeMux, m runtime.Marshaler, w http.ResponseWriter, r *http.Request, err error) {
+	runtime.DefaultHTTPErrorHandler(ctx, mux, m, w, r, postgresStatus(err))
+}
+
+func postgresStatus(err error) error {
+	var pgErr *postgres.Error
+	if !errors.As(postgres.Classify(err), &pgErr) {
+		return err
+	}
+
+	var code codes.Code
+	switch pgErr.Kind {
+	case postgres.ErrNotFound:
+		return status.Error(codes.NotFound, pgErr.Error())
+	case postgres.ErrAlreadyExists:
+		code = codes.AlreadyExists
+	case postgres.ErrForeignKey:
+		code = codes.FailedPrecondition
+	case postgres.ErrNotNull, postgres.ErrCheckViolation:
+		code = codes.InvalidArgument
+	default:
+		return err
+	}
+
+	st := status.New(code, pgErr.Kind.Error())
+	if field := pgErr.Field(); field != "" {
+		withDetails, detailsErr := st.WithDetails(&errdetails.BadRequest{
+			FieldViolations: []*errdetails.BadRequest_FieldViolation{{
+				Field:       field,
+				Description: pgErr.Error(),
+			}},
+		})
+		if detailsErr == nil {
+			st = withDetails
+		}
+	}
+	return st.Err()
+}
+
+func newRequestID() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// validRequestID принимает непустые идентификаторы до maxRequestIDLen символов из печатного ASCII без пробелов.
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLen {
+		return false
+	}
+	for i := range len(id) {
+		if id[i] <= ' ' || id[i] > '~' {
+			return false
+		}
+	}
+	return true
+}
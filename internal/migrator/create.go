@@ -0,0 +1,172 @@
+package migrator
+
+import (
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+	"unicode"
+)
+
+type Type string
+
+const (
+	TypeSQL Type = "sql"
+	TypeGo  Type = "go"
+)
+
+const (
+	// Directory — каталог исходников миграций относительно корня модуля,
+	// в который Create пишет новые файлы.
+	Directory = "migrations"
+
+	timestampFormat = "20060102150405"
+	// Версии меньше этой считаются последовательными (00001, 00002, ...), а не временными метками.
+	minTimestampVersion = 19700101000000
+)
+
+var versionPattern = regexp.MustCompile(`^(\d+)_.+\.(sql|go)$`)
+
+var sqlTemplate = template.Must(template.New("sql").Parse(`-- +goose Up
+-- +goose StatementBegin
+SELECT 'up SQL query';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+SELECT 'down SQL query';
+-- +goose StatementEnd
+`))
+
+var sqlBodyTemplate = template.Must(template.New("sql-body").Parse(`-- +goose Up
+{{.Up}}
+
+-- +goose Down
+{{.Down}}
+`))
+
+var goTemplate = template.Must(template.New("go").Parse(`package migrations
+
+import (
+	"context"
+	"database/sql"
+)
+
+func init() {
+	register({{.Version}}, up{{.Name}}, down{{.Name}})
+}
+
+func up{{.Name}}(ctx context.Context, tx *sql.Tx) error {
+	return nil
+}
+
+func down{{.Name}}(ctx context.Context, tx *sql.Tx) error {
+	return nil
+}
+`))
+
+// Create пишет в dir пустую миграцию типа typ и возвращает путь к файлу. Если в каталоге
+// уже используются последовательные версии, новая миграция получает следующий номер,
+// иначе — временную метку. Go-миграции регистрируются в пакете migrations через register.
+func Create(dir, name string, typ Type) (string, error) {
+	tmpl := sqlTemplate
+	switch typ {
+	case TypeSQL:
+	case TypeGo:
+		tmpl = goTemplate
+	default:
+		return "", fmt.Errorf("migrator: unsupported migration type: %q (valid: sql, go)", typ)
+	}
+
+	return create(dir, name, typ, tmpl, nil)
+}
+
+// CreateSQL пишет в dir SQL-миграцию с запросами up и down и возвращает путь к файлу.
+func CreateSQL(dir, name, up, down string) (string, error) {
+	return create(dir, name, TypeSQL, sqlBodyTemplate, map[string]string{
+		"Up":   strings.TrimSpace(up),
+		"Down": strings.TrimSpace(down),
+	})
+}
+
+func create(dir, name string, typ Type, tmpl *template.Template, extra map[string]string) (string, error) {
+	if len(words(name)) == 0 {
+		return "", fmt.Errorf("migrator: invalid migration name: %q", name)
+	}
+
+	version, err := nextVersion(dir)
+	if err != nil {
+		return "", err
+	}
+
+	path := filepath.Join(dir, fmt.Sprintf("%s_%s.%s", version, snakeCase(name), typ))
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+	if err != nil {
+		return "", fmt.Errorf("migrator: failed to create migration file: %w", err)
+	}
+	defer f.Close()
+
+	vars := map[string]string{
+		"Version": strings.TrimLeft(version, "0"),
+		"Name":    camelCase(name),
+	}
+	maps.Copy(vars, extra)
+	if err := tmpl.Execute(f, vars); err != nil {
+		return "", fmt.Errorf("migrator: failed to write migration file: %w", err)
+	}
+
+	return path, nil
+}
+
+func nextVersion(dir string) (string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return "", fmt.Errorf("migrator: failed to read migrations directory: %w", err)
+	}
+
+	var last int64
+	for _, entry := range entries {
+		match := versionPattern.FindStringSubmatch(entry.Name())
+		if match == nil {
+			continue
+		}
+		version, err := strconv.ParseInt(match[1], 10, 64)
+		if err != nil {
+			continue
+		}
+		last = max(last, version)
+	}
+
+	if last > 0 && last < minTimestampVersion {
+		return fmt.Sprintf("%05d", last+1), nil
+	}
+	return time.Now().UTC().Format(timestampFormat), nil
+}
+
+func snakeCase(name string) string {
+	return strings.Join(words(name), "_")
+}
+
+func camelCase(name string) string {
+	parts := words(name)
+	for i, w := range parts {
+		r := []rune(w)
+		parts[i] = string(unicode.ToUpper(r[0])) + string(r[1:])
+	}
+	return strings.Join(parts, "")
+}
+
+func words(name string) []string {
+	fields := strings.FieldsFunc(name, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	for i, f := range fields {
+		fields[i] = strings.ToLower(f)
+	}
+	return fields
+}
@@ -0,0 +1,154 @@
+package migrator
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/desulaidovich/app/internal/postgres"
+	"github.com/desulaidovich/app/pkg/log"
+)
+
+const (
+	defaultLockTimeout = 5 * time.Minute
+	lockRetryPeriod    = 2 * time.Second
+	lockName           = "migrations"
+)
+
+const lockHolderQuery = `
+SELECT a.pid, COALESCE(a.application_name, ''), COALESCE(host(a.client_addr), ''), a.backend_start
+FROM pg_locks l
+JOIN pg_stat_activity a ON a.pid = l.pid
+WHERE l.locktype = 'advisory'
+  AND l.granted
+  AND l.classid = (($1::bigint >> 32) & x'FFFFFFFF'::bigint)::oid
+  AND l.objid = ($1::bigint & x'FFFFFFFF'::bigint)::oid
+  AND l.objsubid = 1
+LIMIT 1`
+
+// sessionLocker сериализует изменяющие операции мигратора между экземплярами
+// с помощью сессионной advisory-блокировки. Реализует lock.SessionLocker из goose.
+type sessionLocker struct {
+	key     int64
+	timeout time.Duration
+	logger  log.Logger
+}
+
+type lockHolder struct {
+	pid         int32
+	application string
+	clientAddr  string
+	since       time.Time
+}
+
+func (l *sessionLocker) SessionLock(ctx context.Context, conn *sql.Conn) error {
+	ctx, cancel := context.WithTimeout(ctx, l.timeout)
+	defer cancel()
+
+	hostname, _ := os.Hostname()
+	start := time.Now()
+	waited := false
+	for {
+		var locked bool
+		if err := conn.QueryRowContext(ctx, "SELECT pg_try_advisory_lock($1)", l.key).Scan(&locked); err != nil {
+			if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+				return fmt.Errorf("timed out after %s waiting for migration lock", l.timeout)
+			}
+			return fmt.Errorf("failed to acquire migration lock: %w", err)
+		}
+		if locked {
+			logger := l.logger.With(map[string]any{
+				"host":   hostname,
+				"waited": time.Since(start).String(),
+			})
+			if waited {
+				logger.Info("Migration lock acquired")
+			} else {
+				logger.Debug("Migration lock acquired")
+			}
+			return nil
+		}
+		waited = true
+
+		holder, err := l.holder(ctx, conn)
+		fields := map[string]any{"timeout": l.timeout.String()}
+		if err == nil && holder != nil {
+			fields["holder_pid"] = holder.pid
+			fields["holder_application"] = holder.application
+			fields["holder_addr"] = holder.clientAddr
+			fields["holder_since"] = holder.since
+		}
+		l.logger.With(fields).Warn("Migration lock is held by another instance, waiting")
+
+		timer := time.NewTimer(lockRetryPeriod)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+				if holder != nil {
+					return fmt.Errorf("timed out after %s waiting for migration lock held by pid %d (%s, %s)",
+						l.timeout, holder.pid, holder.application, holder.clientAddr)
+				}
+				return fmt.Errorf("timed out after %s waiting for migration lock", l.timeout)
+			}
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+func (l *sessionLocker) SessionUnlock(ctx context.Context, conn *sql.Conn) error {
+	var unlocked bool
+	if err := conn.QueryRowContext(ctx, "SELECT pg_advisory_unlock($1)", l.key).Scan(&unlocked); err != nil {
+		return fmt.Errorf("failed to release migration lock: %w", err)
+	}
+	if !unlocked {
+		return errors.New("migration lock was not held")
+	}
+
+	l.logger.Debug("Migration lock released")
+	return nil
+}
+
+func (l *sessionLocker) holder(ctx context.Context, conn *sql.Conn) (*lockHolder, error) {
+	var h lockHolder
+	err := conn.QueryRowContext(ctx, lockHolderQuery, l.key).Scan(&h.pid, &h.application, &h.clientAddr, &h.since)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &h, nil
+}
+
+// locked выполняет fn под блокировкой миграций на отдельном соединении.
+func (m *Migrator) locked(ctx context.Context, fn func() error) (err error) {
+	conn, err := m.db.Conn(ctx)
+	if err != nil {
+		return fmt.Errorf("migrator: failed to get connection: %w", err)
+	}
+	defer conn.Close()
+
+	if err := m.locker.SessionLock(ctx, conn); err != nil {
+		return fmt.Errorf("migrator: %w", err)
+	}
+	defer func() {
+		if unlockErr := m.locker.SessionUnlock(context.WithoutCancel(ctx), conn); unlockErr != nil {
+			err = errors.Join(err, fmt.Errorf("migrator: %w", unlockErr))
+		}
+	}()
+
+	return fn()
+}
+
+func newSessionLocker(timeout time.Duration, logger log.Logger) *sessionLocker {
+	return &sessionLocker{
+		key:     postgres.AdvisoryLockKey(lockName),
+		timeout: timeout,
+		logger:  logger,
+	}
+}
@@ -3,73 +3,352 @@ package migrator
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
+	"io/fs"
+	"path/filepath"
+	"strings"
+	"time"
 
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/jackc/pgx/v5/stdlib"
 	"github.com/pressly/goose/v3"
+
+	"github.com/desulaidovich/app/migrations"
+	"github.com/desulaidovich/app/pkg/log"
 )
 
 const (
-	Type      = "go"
-	Create    = "up"
-	Delete    = "down"
-	Directory = "./migrations"
+	DirectionUp   = "up"
+	DirectionDown = "down"
 )
 
+// Result — итог применения или отката одной миграции.
+type Result struct {
+	Version   int64
+	Type      Type
+	Path      string
+	Direction string
+	Duration  time.Duration
+	// Empty означает, что в миграции не было ни одного запроса для этого направления.
+	Empty bool
+}
+
+// MigrationStatus — состояние одной миграции.
+type MigrationStatus struct {
+	Version int64  `json:"version"`
+	Name    string `json:"name"`
+	Type    Type   `json:"type"`
+	Path    string `json:"path,omitempty"`
+	// AppliedAt равен nil для неприменённых миграций.
+	AppliedAt *time.Time `json:"applied_at"`
+	Pending   bool       `json:"pending"`
+}
+
+// Migrator применяет SQL- и Go-миграции через goose.Provider без глобального состояния goose.
+// По умолчанию используются миграции, встроенные в бинарник из пакета migrations.
+// Изменяющие операции выполняются под advisory-блокировкой, поэтому несколько
+// экземпляров могут запускать миграции одновременно.
 type Migrator struct {
-	db *sql.DB
+	db          *sql.DB
+	fsys        fs.FS
+	goMigs      []*goose.Migration
+	logger      log.Logger
+	lockTimeout time.Duration
+	locker      *sessionLocker
+	provider    *goose.Provider
+	// sqlPaths — пути SQL-миграций по версиям для записи контрольных сумм.
+	sqlPaths map[int64]string
+}
+
+type Option func(*Migrator) error
+
+// WithFS заменяет встроенные SQL-миграции на миграции из fsys.
+func WithFS(fsys fs.FS) Option {
+	return func(m *Migrator) error {
+		if fsys == nil {
+			return errors.New("fs cannot be nil")
+		}
+		m.fsys = fsys
+		return nil
+	}
+}
+
+// WithGoMigrations заменяет Go-миграции, зарегистрированные в пакете migrations.
+func WithGoMigrations(migs ...*goose.Migration) Option {
+	return func(m *Migrator) error {
+		m.goMigs = migs
+		return nil
+	}
 }
 
-func New(dsn string) (*Migrator, error) {
-	if err := goose.SetDialect("postgres"); err != nil {
-		return nil, fmt.Errorf("migrator: failed to set dialect: %w", err)
+func WithLogger(logger log.Logger) Option {
+	return func(m *Migrator) error {
+		if logger == nil {
+			return errors.New("logger cannot be nil")
+		}
+		m.logger = logger
+		return nil
 	}
+}
+
+// WithLockTimeout ограничивает ожидание блокировки миграций, занятой другим экземпляром.
+func WithLockTimeout(timeout time.Duration) Option {
+	return func(m *Migrator) error {
+		if timeout <= 0 {
+			return errors.New("lockTimeout must be positive")
+		}
+		m.lockTimeout = timeout
+		return nil
+	}
+}
 
+func New(dsn string, opts ...Option) (*Migrator, error) {
 	db, err := sql.Open("pgx", dsn)
 	if err != nil {
 		return nil, fmt.Errorf("migrator: failed to open database: %w", err)
 	}
-	if err := db.Ping(); err != nil {
+
+	return newMigrator(db, opts...)
+}
+
+// NewFromPool создаёт мигратор поверх существующего пула соединений приложения.
+func NewFromPool(pool *pgxpool.Pool, opts ...Option) (*Migrator, error) {
+	if pool == nil {
+		return nil, errors.New("migrator: pool cannot be nil")
+	}
+
+	return newMigrator(stdlib.OpenDBFromPool(pool), opts...)
+}
+
+func newMigrator(db *sql.DB, opts ...Option) (_ *Migrator, err error) {
+	defer func() {
+		if err != nil {
+			_ = db.Close()
+		}
+	}()
+
+	m := &Migrator{
+		db:          db,
+		fsys:        migrations.FS,
+		goMigs:      migrations.GoMigrations(),
+		lockTimeout: defaultLockTimeout,
+		sqlPaths:    make(map[int64]string),
+	}
+	for _, opt := range opts {
+		if err := opt(m); err != nil {
+			return nil, fmt.Errorf("migrator option: %w", err)
+		}
+	}
+	if m.logger == nil {
+		return nil, errors.New("migrator: logger is required")
+	}
+
+	m.locker = newSessionLocker(m.lockTimeout, m.logger)
+
+	// Блокировку берёт сам мигратор (см. locked), а не goose: иначе составные операции
+	// вроде Redo отпускали бы её между шагами.
+	store, err := newChecksumStore(m)
+	if err != nil {
+		return nil, fmt.Errorf("migrator: failed to create store: %w", err)
+	}
+	provider, err := goose.NewProvider(goose.DialectCustom, db, m.fsys,
+		goose.WithStore(store),
+		goose.WithGoMigrations(m.goMigs...),
+		goose.WithDisableGlobalRegistry(true),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("migrator: failed to create provider: %w", err)
+	}
+	m.provider = provider
+	for _, s := range provider.ListSources() {
+		if s.Type == goose.TypeSQL {
+			m.sqlPaths[s.Version] = s.Path
+		}
+	}
+
+	if err := provider.Ping(context.Background()); err != nil {
 		return nil, fmt.Errorf("migrator: failed to ping database: %w", err)
 	}
 
-	return &Migrator{
-		db: db,
-	}, nil
+	return m, nil
+}
+
+// Up применяет все неприменённые миграции. При ошибке возвращает и уже применённые.
+func (m *Migrator) Up(ctx context.Context) ([]Result, error) {
+	var results []Result
+	err := m.locked(ctx, func() error {
+		applied, err := m.provider.Up(ctx)
+		if err != nil {
+			results = partial(err)
+			return fmt.Errorf("migrator: failed to up: %w", err)
+		}
+		results = toResults(applied)
+		return nil
+	})
+	return results, err
+}
+
+// UpByOne применяет одну следующую миграцию.
+func (m *Migrator) UpByOne(ctx context.Context) (*Result, error) {
+	return m.one(ctx, "up by one", m.provider.UpByOne)
+}
+
+// UpTo применяет неприменённые миграции до версии version включительно.
+func (m *Migrator) UpTo(ctx context.Context, version int64) ([]Result, error) {
+	var results []Result
+	err := m.locked(ctx, func() error {
+		applied, err := m.provider.UpTo(ctx, version)
+		if err != nil {
+			results = partial(err)
+			return fmt.Errorf("migrator: failed to up to %d: %w", version, err)
+		}
+		results = toResults(applied)
+		return nil
+	})
+	return results, err
+}
+
+// Down откатывает последнюю применённую миграцию.
+func (m *Migrator) Down(ctx context.Context) (*Result, error) {
+	return m.one(ctx, "down", m.provider.Down)
+}
+
+// DownTo откатывает миграции новее version. DownTo(ctx, 0) откатывает все миграции.
+func (m *Migrator) DownTo(ctx context.Context, version int64) ([]Result, error) {
+	var results []Result
+	err := m.locked(ctx, func() error {
+		rolledBack, err := m.provider.DownTo(ctx, version)
+		if err != nil {
+			results = partial(err)
+			return fmt.Errorf("migrator: failed to down to %d: %w", version, err)
+		}
+		results = toResults(rolledBack)
+		return nil
+	})
+	return results, err
+}
+
+// Redo откатывает и заново применяет последнюю применённую миграцию. Оба шага
+// выполняются под одной блокировкой, чтобы другой экземпляр не вклинился между ними.
+func (m *Migrator) Redo(ctx context.Context) ([]Result, error) {
+	var results []Result
+	err := m.locked(ctx, func() error {
+		down, err := m.provider.Down(ctx)
+		if err != nil {
+			return fmt.Errorf("migrator: failed to redo: %w", err)
+		}
+		results = append(results, toResult(down))
+
+		up, err := m.provider.ApplyVersion(ctx, down.Source.Version, true)
+		if err != nil {
+			return fmt.Errorf("migrator: failed to redo: %w", err)
+		}
+		results = append(results, toResult(up))
+		return nil
+	})
+	return results, err
 }
 
-func (m *Migrator) Create(name string) error {
-	if err := goose.Create(m.db, Directory, name, Type); err != nil {
-		return fmt.Errorf("migrator: failed to create migration: %w", err)
+// Reset откатывает все применённые миграции.
+func (m *Migrator) Reset(ctx context.Context) ([]Result, error) {
+	return m.DownTo(ctx, 0)
+}
+
+func (m *Migrator) Status(ctx context.Context) ([]MigrationStatus, error) {
+	statuses, err := m.provider.Status(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("migrator: failed to get status: %w", err)
+	}
+
+	out := make([]MigrationStatus, 0, len(statuses))
+	for _, s := range statuses {
+		status := MigrationStatus{
+			Version: s.Source.Version,
+			Name:    migrationName(s.Source.Path),
+			Type:    Type(s.Source.Type),
+			Path:    s.Source.Path,
+			Pending: s.State == goose.StatePending,
+		}
+		if !status.Pending {
+			appliedAt := s.AppliedAt
+			status.AppliedAt = &appliedAt
+		}
+		out = append(out, status)
 	}
+	return out, nil
+}
 
-	return nil
+func (m *Migrator) Version(ctx context.Context) (int64, error) {
+	version, err := m.provider.GetDBVersion(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("migrator: failed to get version: %w", err)
+	}
+	return version, nil
 }
 
-func (m *Migrator) Up(ctx context.Context) error {
-	if err := goose.RunContext(ctx, Create, m.db, Directory); err != nil {
-		return fmt.Errorf("migrator: failed to up: %w", err)
+// Latest возвращает версию последней известной миграции.
+func (m *Migrator) Latest() (int64, error) {
+	sources := m.provider.ListSources()
+	if len(sources) == 0 {
+		return 0, errors.New("migrator: no migrations found")
 	}
-	return nil
+	return sources[len(sources)-1].Version, nil
 }
 
-func (m *Migrator) Down(ctx context.Context) error {
-	if err := goose.RunContext(ctx, Delete, m.db, Directory); err != nil {
-		return fmt.Errorf("migrator: failed to down: %w", err)
+func (m *Migrator) Close() error {
+	return m.provider.Close()
+}
+
+// one выполняет под блокировкой операцию над одной миграцией.
+func (m *Migrator) one(ctx context.Context, op string, fn func(context.Context) (*goose.MigrationResult, error)) (*Result, error) {
+	var result *Result
+	err := m.locked(ctx, func() error {
+		r, err := fn(ctx)
+		if err != nil {
+			return fmt.Errorf("migrator: failed to %s: %w", op, err)
+		}
+		res := toResult(r)
+		result = &res
+		return nil
+	})
+	return result, err
+}
+
+// migrationName возвращает имя миграции из пути вида 20261018100000_create_jobs.sql.
+// У Go-миграций без файла имени нет.
+func migrationName(path string) string {
+	base := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	_, name, ok := strings.Cut(base, "_")
+	if !ok {
+		return ""
 	}
-	return nil
+	return name
 }
 
-func (m *Migrator) Status(ctx context.Context) error {
-	if err := goose.RunContext(ctx, "status", m.db, Directory); err != nil {
-		return fmt.Errorf("migrator: failed to get status: %w", err)
+func partial(err error) []Result {
+	var partialErr *goose.PartialError
+	if !errors.As(err, &partialErr) {
+		return nil
 	}
-	return nil
+	return toResults(partialErr.Applied)
 }
 
-func (m *Migrator) Version(_ context.Context) (int64, error) {
-	return goose.GetDBVersion(m.db)
+func toResults(results []*goose.MigrationResult) []Result {
+	out := make([]Result, 0, len(results))
+	for _, r := range results {
+		out = append(out, toResult(r))
+	}
+	return out
 }
 
-func (m *Migrator) Close() error {
-	return m.db.Close()
+func toResult(r *goose.MigrationResult) Result {
+	return Result{
+		Version:   r.Source.Version,
+		Type:      Type(r.Source.Type),
+		Path:      r.Source.Path,
+		Direction: r.Direction,
+		Duration:  r.Duration,
+		Empty:     r.Empty,
+	}
 }
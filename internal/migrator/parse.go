@@ -0,0 +1,143 @@
+package migrator
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+const annotationPrefix = "-- +goose "
+
+// sqlMigration — SQL-миграция, разобранная по аннотациям goose.
+type sqlMigration struct {
+	Up   []string
+	Down []string
+	// NoTx означает, что миграция выполняется вне транзакции (-- +goose NO TRANSACTION).
+	NoTx bool
+}
+
+func (m *sqlMigration) statements(direction string) []string {
+	if direction == DirectionDown {
+		return m.Down
+	}
+	return m.Up
+}
+
+// parseSQL разбирает файл миграции так же, как goose: запросы разделяются точкой
+// с запятой в конце строки, кроме блоков StatementBegin/StatementEnd.
+func parseSQL(r io.Reader) (*sqlMigration, error) {
+	var (
+		m         sqlMigration
+		direction string
+		buf       strings.Builder
+		inBlock   bool
+		seenUp    bool
+		lineNo    int
+	)
+
+	flush := func() {
+		if stmt := strings.TrimSpace(buf.String()); stmt != "" {
+			if direction == DirectionUp {
+				m.Up = append(m.Up, stmt)
+			} else {
+				m.Down = append(m.Down, stmt)
+			}
+		}
+		buf.Reset()
+	}
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		lineNo++
+		line := scanner.Text()
+		trimmed := strings.TrimSpace(line)
+
+		if annotation, ok := strings.CutPrefix(trimmed, annotationPrefix); ok {
+			switch strings.ToUpper(strings.TrimSpace(annotation)) {
+			case "UP":
+				if seenUp {
+					return nil, fmt.Errorf("line %d: duplicate '-- +goose Up'", lineNo)
+				}
+				seenUp = true
+				direction = DirectionUp
+			case "DOWN":
+				if !seenUp {
+					return nil, fmt.Errorf("line %d: '-- +goose Down' before '-- +goose Up'", lineNo)
+				}
+				if inBlock {
+					return nil, fmt.Errorf("line %d: missing '-- +goose StatementEnd'", lineNo)
+				}
+				if strings.TrimSpace(buf.String()) != "" {
+					return nil, fmt.Errorf("line %d: statement is not terminated with a semicolon", lineNo)
+				}
+				buf.Reset()
+				direction = DirectionDown
+			case "STATEMENTBEGIN":
+				if direction == "" {
+					return nil, fmt.Errorf("line %d: StatementBegin before '-- +goose Up'", lineNo)
+				}
+				if inBlock {
+					return nil, fmt.Errorf("line %d: nested StatementBegin", lineNo)
+				}
+				if strings.TrimSpace(buf.String()) != "" {
+					return nil, fmt.Errorf("line %d: statement is not terminated with a semicolon", lineNo)
+				}
+				inBlock = true
+			case "STATEMENTEND":
+				if !inBlock {
+					return nil, fmt.Errorf("line %d: StatementEnd without StatementBegin", lineNo)
+				}
+				inBlock = false
+				flush()
+			case "NO TRANSACTION":
+				m.NoTx = true
+			case "ENVSUB ON", "ENVSUB OFF":
+			default:
+				return nil, fmt.Errorf("line %d: unknown annotation %q", lineNo, trimmed)
+			}
+			continue
+		}
+
+		if direction == "" {
+			if trimmed != "" && !strings.HasPrefix(trimmed, "--") {
+				return nil, fmt.Errorf("line %d: statement before '-- +goose Up'", lineNo)
+			}
+			continue
+		}
+		if !inBlock && buf.Len() == 0 && (trimmed == "" || strings.HasPrefix(trimmed, "--")) {
+			continue
+		}
+
+		buf.WriteString(line)
+		buf.WriteByte('\n')
+		if !inBlock && endsWithSemicolon(line) {
+			flush()
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	switch {
+	case !seenUp:
+		return nil, errors.New("missing '-- +goose Up' annotation")
+	case inBlock:
+		return nil, errors.New("missing '-- +goose StatementEnd'")
+	case strings.TrimSpace(buf.String()) != "":
+		return nil, errors.New("last statement is not terminated with a semicolon")
+	}
+
+	return &m, nil
+}
+
+// endsWithSemicolon проверяет, заканчивается ли строка на ';' без учёта
+// завершающего однострочного комментария.
+func endsWithSemicolon(line string) bool {
+	if i := strings.Index(line, "--"); i >= 0 {
+		line = line[:i]
+	}
+	return strings.HasSuffix(strings.TrimSpace(line), ";")
+}
@@ -0,0 +1,148 @@
+package migrator
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestParseSQL(t *testing.T) {
+	tests := []struct {
+		name     string
+		src      string
+		wantUp   []string
+		wantDown []string
+		wantNoTx bool
+		wantErr  string
+	}{
+		{
+			name: "statements split by trailing semicolon",
+			src: `-- +goose Up
+-- comment before the first statement
+CREATE TABLE users (
+    id BIGINT PRIMARY KEY -- trailing comment;
+);
+CREATE INDEX users_id_idx ON users (id); -- index
+
+-- +goose Down
+DROP TABLE users;
+`,
+			wantUp: []string{
+				"CREATE TABLE users (\n    id BIGINT PRIMARY KEY -- trailing comment;\n);",
+				"CREATE INDEX users_id_idx ON users (id); -- index",
+			},
+			wantDown: []string{"DROP TABLE users;"},
+		},
+		{
+			name: "statement block keeps inner semicolons",
+			src: `-- +goose Up
+-- +goose StatementBegin
+CREATE FUNCTION touch() RETURNS trigger AS $$
+BEGIN
+    NEW.updated_at = now();
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+-- +goose StatementEnd
+
+-- +goose Down
+DROP FUNCTION touch;
+`,
+			wantUp: []string{
+				"CREATE FUNCTION touch() RETURNS trigger AS $$\nBEGIN\n    NEW.updated_at = now();\n    RETURN NEW;\nEND;\n$$ LANGUAGE plpgsql;",
+			},
+			wantDown: []string{"DROP FUNCTION touch;"},
+		},
+		{
+			name: "no transaction and lowercase annotations",
+			src: `-- +goose NO TRANSACTION
+-- +goose up
+CREATE INDEX CONCURRENTLY jobs_run_at_idx ON jobs (run_at);
+-- +goose down
+DROP INDEX CONCURRENTLY jobs_run_at_idx;
+`,
+			wantUp:   []string{"CREATE INDEX CONCURRENTLY jobs_run_at_idx ON jobs (run_at);"},
+			wantDown: []string{"DROP INDEX CONCURRENTLY jobs_run_at_idx;"},
+			wantNoTx: true,
+		},
+		{
+			name:   "up only",
+			src:    "-- +goose Up\nSELECT 1;\n",
+			wantUp: []string{"SELECT 1;"},
+		},
+		{
+			name:    "missing up",
+			src:     "CREATE TABLE users (id INT);\n",
+			wantErr: "line 1: statement before '-- +goose Up'",
+		},
+		{
+			name:    "empty file",
+			src:     "",
+			wantErr: "missing '-- +goose Up' annotation",
+		},
+		{
+			name:    "down before up",
+			src:     "-- +goose Down\nDROP TABLE users;\n",
+			wantErr: "line 1: '-- +goose Down' before '-- +goose Up'",
+		},
+		{
+			name:    "duplicate up",
+			src:     "-- +goose Up\nSELECT 1;\n-- +goose Up\n",
+			wantErr: "line 3: duplicate '-- +goose Up'",
+		},
+		{
+			name:    "unterminated statement before down",
+			src:     "-- +goose Up\nSELECT 1\n-- +goose Down\n",
+			wantErr: "line 3: statement is not terminated with a semicolon",
+		},
+		{
+			name:    "unterminated last statement",
+			src:     "-- +goose Up\nSELECT 1\n",
+			wantErr: "last statement is not terminated with a semicolon",
+		},
+		{
+			name:    "missing statement end",
+			src:     "-- +goose Up\n-- +goose StatementBegin\nSELECT 1;\n",
+			wantErr: "missing '-- +goose StatementEnd'",
+		},
+		{
+			name:    "statement end without begin",
+			src:     "-- +goose Up\n-- +goose StatementEnd\n",
+			wantErr: "line 2: StatementEnd without StatementBegin",
+		},
+		{
+			name:    "nested statement begin",
+			src:     "-- +goose Up\n-- +goose StatementBegin\n-- +goose StatementBegin\n",
+			wantErr: "line 3: nested StatementBegin",
+		},
+		{
+			name:    "unknown annotation",
+			src:     "-- +goose Up\n-- +goose Sideways\n",
+			wantErr: `line 2: unknown annotation "-- +goose Sideways"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseSQL(strings.NewReader(tt.src))
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Fatalf("error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !slices.Equal(got.Up, tt.wantUp) {
+				t.Errorf("Up = %q, want %q", got.Up, tt.wantUp)
+			}
+			if !slices.Equal(got.Down, tt.wantDown) {
+				t.Errorf("Down = %q, want %q", got.Down, tt.wantDown)
+			}
+			if got.NoTx != tt.wantNoTx {
+				t.Errorf("NoTx = %t, want %t", got.NoTx, tt.wantNoTx)
+			}
+		})
+	}
+}
@@ -0,0 +1,120 @@
+package migrator
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+)
+
+type Command string
+
+const (
+	CommandUp      Command = "up"
+	CommandUpByOne Command = "up-by-one"
+	CommandUpTo    Command = "up-to"
+	CommandDown    Command = "down"
+	CommandDownTo  Command = "down-to"
+	CommandRedo    Command = "redo"
+	CommandReset   Command = "reset"
+)
+
+// Step — миграция, которую выполнит команда, и её запросы.
+type Step struct {
+	Version   int64
+	Type      Type
+	Path      string
+	Direction string
+	// Statements пуст для Go-миграций: их код нельзя показать без выполнения.
+	Statements []string
+	NoTx       bool
+}
+
+// Plan возвращает шаги, которые выполнит команда cmd, ничего не меняя в базе.
+// target используется командами up-to и down-to.
+func (m *Migrator) Plan(ctx context.Context, cmd Command, target int64) ([]Step, error) {
+	statuses, err := m.Status(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var pending, applied []MigrationStatus
+	for _, s := range statuses {
+		if s.Pending {
+			pending = append(pending, s)
+		} else {
+			applied = append(applied, s)
+		}
+	}
+	slices.SortFunc(applied, func(a, b MigrationStatus) int { return cmp.Compare(b.Version, a.Version) })
+
+	var (
+		up   []MigrationStatus
+		down []MigrationStatus
+	)
+	switch cmd {
+	case CommandUp:
+		up = pending
+	case CommandUpByOne:
+		up = pending[:min(1, len(pending))]
+	case CommandUpTo:
+		for _, s := range pending {
+			if s.Version <= target {
+				up = append(up, s)
+			}
+		}
+	case CommandDown:
+		down = applied[:min(1, len(applied))]
+	case CommandDownTo:
+		for _, s := range applied {
+			if s.Version > target {
+				down = append(down, s)
+			}
+		}
+	case CommandRedo:
+		down = applied[:min(1, len(applied))]
+		up = down
+	case CommandReset:
+		down = applied
+	default:
+		return nil, fmt.Errorf("migrator: unsupported command: %q", cmd)
+	}
+
+	steps := make([]Step, 0, len(up)+len(down))
+	for _, s := range down {
+		step, err := m.step(s, DirectionDown)
+		if err != nil {
+			return nil, err
+		}
+		steps = append(steps, step)
+	}
+	for _, s := range up {
+		step, err := m.step(s, DirectionUp)
+		if err != nil {
+			return nil, err
+		}
+		steps = append(steps, step)
+	}
+	return steps, nil
+}
+
+func (m *Migrator) step(s MigrationStatus, direction string) (Step, error) {
+	step := Step{Version: s.Version, Type: s.Type, Path: s.Path, Direction: direction}
+	if s.Type != TypeSQL {
+		return step, nil
+	}
+
+	f, err := m.fsys.Open(s.Path)
+	if err != nil {
+		return Step{}, fmt.Errorf("migrator: failed to open %s: %w", s.Path, err)
+	}
+	defer f.Close()
+
+	parsed, err := parseSQL(f)
+	if err != nil {
+		return Step{}, fmt.Errorf("migrator: %s: %w", s.Path, err)
+	}
+	step.Statements = parsed.statements(direction)
+	step.NoTx = parsed.NoTx
+	return step, nil
+}
@@ -0,0 +1,102 @@
+package migrator
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+)
+
+// SnapshotFile — имя закоммиченного снимка схемы в каталоге Directory.
+const SnapshotFile = "schema.snapshot"
+
+// snapshotQuery описывает таблицы, столбцы, ограничения и индексы текущей схемы
+// по одному объекту в строке. Служебные таблицы goose_* исключены.
+const snapshotQuery = `
+WITH tables AS (
+    SELECT c.oid, c.relname
+    FROM pg_class c
+    JOIN pg_namespace n ON n.oid = c.relnamespace
+    WHERE n.nspname = current_schema()
+      AND c.relkind IN ('r', 'p')
+      AND c.relname NOT LIKE 'goose\_%'
+)
+SELECT 'table ' || t.relname
+FROM tables t
+UNION ALL
+SELECT 'column ' || t.relname || '.' || a.attname || ' ' || format_type(a.atttypid, a.atttypmod)
+    || CASE WHEN a.attnotnull THEN ' not null' ELSE '' END
+    || COALESCE(' default ' || pg_get_expr(d.adbin, d.adrelid), '')
+FROM tables t
+JOIN pg_attribute a ON a.attrelid = t.oid AND a.attnum > 0 AND NOT a.attisdropped
+LEFT JOIN pg_attrdef d ON d.adrelid = a.attrelid AND d.adnum = a.attnum
+UNION ALL
+SELECT 'constraint ' || t.relname || '.' || con.conname || ' ' || pg_get_constraintdef(con.oid)
+FROM tables t
+JOIN pg_constraint con ON con.conrelid = t.oid
+UNION ALL
+SELECT 'index ' || pg_get_indexdef(i.indexrelid)
+FROM tables t
+JOIN pg_index i ON i.indrelid = t.oid
+ORDER BY 1`
+
+// Snapshot возвращает описание живой схемы из pg_catalog.
+func (m *Migrator) Snapshot(ctx context.Context) ([]string, error) {
+	rows, err := m.db.QueryContext(ctx, snapshotQuery)
+	if err != nil {
+		return nil, fmt.Errorf("migrator: failed to read schema: %w", err)
+	}
+	defer rows.Close()
+
+	var lines []string
+	for rows.Next() {
+		var line string
+		if err := rows.Scan(&line); err != nil {
+			return nil, fmt.Errorf("migrator: failed to scan schema: %w", err)
+		}
+		lines = append(lines, line)
+	}
+	return lines, rows.Err()
+}
+
+// WriteSnapshot записывает снимок схемы в w.
+func WriteSnapshot(w io.Writer, lines []string) error {
+	for _, line := range lines {
+		if _, err := fmt.Fprintln(w, line); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ReadSnapshot читает снимок схемы, пропуская пустые строки и комментарии.
+func ReadSnapshot(r io.Reader) ([]string, error) {
+	var lines []string
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		lines = append(lines, line)
+	}
+	return lines, scanner.Err()
+}
+
+// CompareSnapshot возвращает объекты, которые есть в снимке, но отсутствуют в живой
+// схеме (missing), и объекты живой схемы, которых нет в снимке (unexpected).
+func CompareSnapshot(expected, actual []string) (missing, unexpected []string) {
+	for _, line := range expected {
+		if !slices.Contains(actual, line) {
+			missing = append(missing, line)
+		}
+	}
+	for _, line := range actual {
+		if !slices.Contains(expected, line) {
+			unexpected = append(unexpected, line)
+		}
+	}
+	return missing, unexpected
+}
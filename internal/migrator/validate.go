@@ -0,0 +1,189 @@
+package migrator
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/pressly/goose/v3"
+)
+
+// Validate разбирает все SQL-миграции в fsys и проверяет версии вместе с Go-миграциями,
+// ничего не применяя. Возвращает все найденные ошибки сразу.
+func Validate(fsys fs.FS, goMigs []*goose.Migration) error {
+	names, err := fs.Glob(fsys, "*.sql")
+	if err != nil {
+		return fmt.Errorf("migrator: failed to list migrations: %w", err)
+	}
+
+	var (
+		errs     []error
+		versions = make(map[int64]string)
+	)
+	for _, name := range names {
+		version, err := parseVersion(name)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if existing, ok := versions[version]; ok {
+			errs = append(errs, fmt.Errorf("%s: duplicate version %d (also %s)", name, version, existing))
+		}
+		versions[version] = name
+
+		if err := validateFile(fsys, name); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", name, err))
+		}
+	}
+
+	for _, m := range goMigs {
+		if existing, ok := versions[m.Version]; ok {
+			errs = append(errs, fmt.Errorf("go migration %d: duplicate version (also %s)", m.Version, existing))
+		}
+		versions[m.Version] = fmt.Sprintf("go migration %d", m.Version)
+	}
+
+	if len(versions) == 0 {
+		errs = append(errs, errors.New("no migrations found"))
+	}
+	return errors.Join(errs...)
+}
+
+func validateFile(fsys fs.FS, name string) error {
+	f, err := fsys.Open(name)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	_, err = parseSQL(f)
+	return err
+}
+
+func parseVersion(name string) (int64, error) {
+	prefix, _, ok := strings.Cut(path.Base(name), "_")
+	if !ok {
+		return 0, fmt.Errorf("%s: file name must be <version>_<name>.<ext>", name)
+	}
+	version, err := strconv.ParseInt(prefix, 10, 64)
+	if err != nil || version < 1 {
+		return 0, fmt.Errorf("%s: invalid version %q", name, prefix)
+	}
+	return version, nil
+}
+
+// Rename — переименование миграции командой fix.
+type Rename struct {
+	From string
+	To   string
+}
+
+// PlanFix возвращает переименования, которые выполнит Fix, ничего не меняя.
+// applied — версии, применённые в базе (см. Migrator.Applied): переименовать такую
+// миграцию нельзя, goose примет её за новую и применит повторно.
+func PlanFix(dir string, applied []int64) ([]Rename, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("migrator: failed to read migrations directory: %w", err)
+	}
+
+	type file struct {
+		name    string
+		version int64
+	}
+	var (
+		timestamped []file
+		last        int64
+	)
+	for _, entry := range entries {
+		if !versionPattern.MatchString(entry.Name()) {
+			continue
+		}
+		version, err := parseVersion(entry.Name())
+		if err != nil {
+			return nil, fmt.Errorf("migrator: %w", err)
+		}
+		if version >= minTimestampVersion {
+			timestamped = append(timestamped, file{entry.Name(), version})
+		} else {
+			last = max(last, version)
+		}
+	}
+	slices.SortFunc(timestamped, func(a, b file) int { return cmp.Compare(a.version, b.version) })
+
+	var errs []error
+	for _, f := range timestamped {
+		if slices.Contains(applied, f.version) {
+			errs = append(errs, fmt.Errorf("migrator: %s is already applied and cannot be renumbered", f.name))
+		}
+	}
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+
+	renames := make([]Rename, 0, len(timestamped))
+	for i, f := range timestamped {
+		prefix := strconv.FormatInt(f.version, 10)
+		renames = append(renames, Rename{
+			From: filepath.Join(dir, f.name),
+			To:   filepath.Join(dir, fmt.Sprintf("%05d", last+int64(i)+1)+strings.TrimPrefix(f.name, prefix)),
+		})
+	}
+	return renames, nil
+}
+
+// Fix переводит миграции с версиями-временными метками на последовательные номера,
+// продолжая последний существующий номер. В Go-миграциях обновляется и версия в register.
+// Если хотя бы одна из них уже применена, Fix ничего не переименовывает и возвращает ошибку,
+// поэтому fix выполняют до выкладки новых миграций.
+func Fix(dir string, applied []int64) ([]Rename, error) {
+	renames, err := PlanFix(dir, applied)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, r := range renames {
+		if filepath.Ext(r.From) == ".go" {
+			if err := fixGoVersion(r); err != nil {
+				return renames[:i], err
+			}
+		}
+		if err := os.Rename(r.From, r.To); err != nil {
+			return renames[:i], fmt.Errorf("migrator: failed to rename %s: %w", r.From, err)
+		}
+	}
+	return renames, nil
+}
+
+func fixGoVersion(r Rename) error {
+	from, err := parseVersion(r.From)
+	if err != nil {
+		return err
+	}
+	to, err := parseVersion(r.To)
+	if err != nil {
+		return err
+	}
+
+	src, err := os.ReadFile(r.From)
+	if err != nil {
+		return fmt.Errorf("migrator: failed to read %s: %w", r.From, err)
+	}
+	old := fmt.Sprintf("register(%d,", from)
+	if !strings.Contains(string(src), old) {
+		return fmt.Errorf("migrator: %s: register(%d, ...) call not found", r.From, from)
+	}
+	src = []byte(strings.Replace(string(src), old, fmt.Sprintf("register(%d,", to), 1))
+
+	if err := os.WriteFile(r.From, src, 0o644); err != nil {
+		return fmt.Errorf("migrator: failed to write %s: %w", r.From, err)
+	}
+	return nil
+}
@@ -0,0 +1,112 @@
+package migrator
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestPlanFix(t *testing.T) {
+	tests := []struct {
+		name    string
+		files   []string
+		applied []int64
+		want    []Rename
+		wantErr []string
+	}{
+		{
+			name:  "continues the last sequential version in timestamp order",
+			files: []string{"00001_init.sql", "00002_users.sql", "20261018130000_b.sql", "20261018120000_a.go", "README.md"},
+			want: []Rename{
+				{From: "20261018120000_a.go", To: "00003_a.go"},
+				{From: "20261018130000_b.sql", To: "00004_b.sql"},
+			},
+		},
+		{
+			name:  "only timestamped migrations",
+			files: []string{"20261018120000_a.sql"},
+			want:  []Rename{{From: "20261018120000_a.sql", To: "00001_a.sql"}},
+		},
+		{
+			name:    "nothing to rename",
+			files:   []string{"00001_init.sql"},
+			applied: []int64{1},
+			want:    []Rename{},
+		},
+		{
+			name:    "applied timestamped migrations are refused",
+			files:   []string{"00001_init.sql", "20261018120000_a.sql", "20261018130000_b.sql", "20261018140000_c.sql"},
+			applied: []int64{1, 20261018120000, 20261018130000},
+			wantErr: []string{
+				"20261018120000_a.sql is already applied",
+				"20261018130000_b.sql is already applied",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			for _, name := range tt.files {
+				if err := os.WriteFile(filepath.Join(dir, name), nil, 0o644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			got, err := PlanFix(dir, tt.applied)
+			if len(tt.wantErr) > 0 {
+				if err == nil {
+					t.Fatalf("expected errors %q, got renames %v", tt.wantErr, got)
+				}
+				for _, want := range tt.wantErr {
+					if !strings.Contains(err.Error(), want) {
+						t.Errorf("error %q does not contain %q", err, want)
+					}
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			want := make([]Rename, 0, len(tt.want))
+			for _, r := range tt.want {
+				want = append(want, Rename{From: filepath.Join(dir, r.From), To: filepath.Join(dir, r.To)})
+			}
+			if !slices.Equal(got, want) {
+				t.Errorf("PlanFix = %v, want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestFixRewritesGoVersion(t *testing.T) {
+	dir := t.TempDir()
+	from := filepath.Join(dir, "20261018120000_backfill.go")
+	src := "package migrations\n\nfunc init() {\n\tregister(20261018120000, upBackfill, downBackfill)\n}\n"
+	if err := os.WriteFile(from, []byte(src), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	renames, err := Fix(dir, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	to := filepath.Join(dir, "00001_backfill.go")
+	if want := []Rename{{From: from, To: to}}; !slices.Equal(renames, want) {
+		t.Fatalf("Fix = %v, want %v", renames, want)
+	}
+
+	data, err := os.ReadFile(to)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), "register(1, upBackfill, downBackfill)") {
+		t.Errorf("register version not rewritten:\n%s", data)
+	}
+	if _, err := os.Stat(from); !os.IsNotExist(err) {
+		t.Errorf("%s still exists", from)
+	}
+}
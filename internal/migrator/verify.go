@@ -0,0 +1,254 @@
+package migrator
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/fs"
+	"slices"
+
+	"github.com/pressly/goose/v3"
+	"github.com/pressly/goose/v3/database"
+)
+
+const checksumTable = "goose_db_checksums"
+
+// VerifyReport — расхождения между применёнными миграциями и миграциями в бинарнике.
+type VerifyReport struct {
+	// Modified — применённые миграции, файл которых изменился после применения.
+	Modified []int64
+	// Missing — известные миграции, пропущенные в базе, хотя более новые уже применены.
+	Missing []int64
+	// Unknown — применённые в базе версии, для которых нет миграции.
+	Unknown []int64
+	// Unrecorded — применённые миграции без сохранённой контрольной суммы
+	// (применены до её появления или в обход мигратора). Их изменение не обнаружить.
+	Unrecorded []int64
+}
+
+func (r *VerifyReport) OK() bool {
+	return len(r.Modified) == 0 && len(r.Missing) == 0 && len(r.Unknown) == 0
+}
+
+// Verify сверяет применённые версии и их контрольные суммы с текущими файлами миграций.
+// Verify ничего не меняет в базе: если таблицы контрольных сумм ещё нет, все применённые
+// SQL-миграции попадают в Unrecorded.
+func (m *Migrator) Verify(ctx context.Context) (*VerifyReport, error) {
+	applied, err := m.appliedVersions(ctx)
+	if err != nil {
+		return nil, err
+	}
+	recorded, err := m.recordedChecksums(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return m.verify(applied, recorded, m.provider.ListSources())
+}
+
+// verify сравнивает применённые версии и сохранённые контрольные суммы с источниками миграций.
+func (m *Migrator) verify(applied []int64, recorded map[int64]string, list []*goose.Source) (*VerifyReport, error) {
+	sources := make(map[int64]Type)
+	paths := make(map[int64]string)
+	for _, s := range list {
+		sources[s.Version] = Type(s.Type)
+		paths[s.Version] = s.Path
+	}
+
+	var (
+		report  VerifyReport
+		latest  int64
+		isKnown = make(map[int64]bool)
+	)
+	for _, version := range applied {
+		latest = max(latest, version)
+		isKnown[version] = true
+
+		typ, ok := sources[version]
+		switch {
+		case !ok:
+			report.Unknown = append(report.Unknown, version)
+		case typ != TypeSQL:
+			// Go-миграции не имеют файла во встроенной FS, сверять нечего.
+		default:
+			sum, ok := recorded[version]
+			if !ok {
+				report.Unrecorded = append(report.Unrecorded, version)
+				continue
+			}
+			current, err := m.checksum(paths[version])
+			if err != nil {
+				return nil, err
+			}
+			if current != sum {
+				report.Modified = append(report.Modified, version)
+			}
+		}
+	}
+
+	for version := range sources {
+		if !isKnown[version] && version < latest {
+			report.Missing = append(report.Missing, version)
+		}
+	}
+	slices.Sort(report.Missing)
+
+	return &report, nil
+}
+
+// checksumStore — хранилище версий goose, которое вместе с версией записывает контрольную
+// сумму SQL-миграции. Insert и Delete вызываются в транзакции миграции и под блокировкой
+// мигратора, поэтому сумма не расходится с применённой версией, даже если процесс упадёт
+// посреди Up.
+type checksumStore struct {
+	database.StoreExtender
+	m *Migrator
+}
+
+func newChecksumStore(m *Migrator) (*checksumStore, error) {
+	store, err := database.NewStore(database.DialectPostgres, goose.DefaultTablename)
+	if err != nil {
+		return nil, err
+	}
+	// TableExists из StoreExtender нужен goose, чтобы не создавать таблицу версий повторно.
+	extender, ok := store.(database.StoreExtender)
+	if !ok {
+		return nil, fmt.Errorf("store %T does not implement database.StoreExtender", store)
+	}
+	return &checksumStore{StoreExtender: extender, m: m}, nil
+}
+
+func (s *checksumStore) Insert(ctx context.Context, db database.DBTxConn, req database.InsertRequest) error {
+	if err := s.StoreExtender.Insert(ctx, db, req); err != nil {
+		return err
+	}
+
+	path, ok := s.m.sqlPaths[req.Version]
+	if !ok {
+		return nil
+	}
+	sum, err := s.m.checksum(path)
+	if err != nil {
+		return err
+	}
+	if err := ensureChecksumTable(ctx, db); err != nil {
+		return err
+	}
+	if _, err := db.ExecContext(ctx, `
+		INSERT INTO `+checksumTable+` (version_id, path, checksum) VALUES ($1, $2, $3)
+		ON CONFLICT (version_id) DO UPDATE
+		SET path = EXCLUDED.path, checksum = EXCLUDED.checksum, recorded_at = now()`,
+		req.Version, path, sum,
+	); err != nil {
+		return fmt.Errorf("migrator: failed to record checksum of %d: %w", req.Version, err)
+	}
+	return nil
+}
+
+func (s *checksumStore) Delete(ctx context.Context, db database.DBTxConn, version int64) error {
+	if err := s.StoreExtender.Delete(ctx, db, version); err != nil {
+		return err
+	}
+
+	if err := ensureChecksumTable(ctx, db); err != nil {
+		return err
+	}
+	if _, err := db.ExecContext(ctx, "DELETE FROM "+checksumTable+" WHERE version_id = $1", version); err != nil {
+		return fmt.Errorf("migrator: failed to delete checksum of %d: %w", version, err)
+	}
+	return nil
+}
+
+func ensureChecksumTable(ctx context.Context, db database.DBTxConn) error {
+	if _, err := db.ExecContext(ctx, `
+		CREATE TABLE IF NOT EXISTS `+checksumTable+` (
+			version_id  BIGINT PRIMARY KEY,
+			path        TEXT        NOT NULL,
+			checksum    TEXT        NOT NULL,
+			recorded_at TIMESTAMPTZ NOT NULL DEFAULT now()
+		)`); err != nil {
+		return fmt.Errorf("migrator: failed to create checksum table: %w", err)
+	}
+	return nil
+}
+
+// Applied возвращает версии, применённые в базе, в порядке возрастания.
+func (m *Migrator) Applied(ctx context.Context) ([]int64, error) {
+	return m.appliedVersions(ctx)
+}
+
+// appliedVersions возвращает применённые версии по таблице goose в порядке возрастания.
+// Если миграции ещё не применялись и таблицы goose нет, возвращается пустой список.
+func (m *Migrator) appliedVersions(ctx context.Context) ([]int64, error) {
+	exists, err := m.tableExists(ctx, "goose_db_version")
+	if err != nil || !exists {
+		return nil, err
+	}
+
+	rows, err := m.db.QueryContext(ctx, `
+		SELECT version_id FROM (
+			SELECT DISTINCT ON (version_id) version_id, is_applied
+			FROM goose_db_version
+			WHERE version_id > 0
+			ORDER BY version_id, id DESC
+		) v
+		WHERE is_applied
+		ORDER BY version_id`)
+	if err != nil {
+		return nil, fmt.Errorf("migrator: failed to list applied versions: %w", err)
+	}
+	defer rows.Close()
+
+	var versions []int64
+	for rows.Next() {
+		var v int64
+		if err := rows.Scan(&v); err != nil {
+			return nil, fmt.Errorf("migrator: failed to scan version: %w", err)
+		}
+		versions = append(versions, v)
+	}
+	return versions, rows.Err()
+}
+
+func (m *Migrator) tableExists(ctx context.Context, table string) (bool, error) {
+	var exists bool
+	if err := m.db.QueryRowContext(ctx, "SELECT to_regclass($1) IS NOT NULL", table).Scan(&exists); err != nil {
+		return false, fmt.Errorf("migrator: failed to check table %s: %w", table, err)
+	}
+	return exists, nil
+}
+
+func (m *Migrator) recordedChecksums(ctx context.Context) (map[int64]string, error) {
+	exists, err := m.tableExists(ctx, checksumTable)
+	if err != nil || !exists {
+		return nil, err
+	}
+
+	rows, err := m.db.QueryContext(ctx, "SELECT version_id, checksum FROM "+checksumTable)
+	if err != nil {
+		return nil, fmt.Errorf("migrator: failed to load checksums: %w", err)
+	}
+	defer rows.Close()
+
+	sums := make(map[int64]string)
+	for rows.Next() {
+		var (
+			version int64
+			sum     string
+		)
+		if err := rows.Scan(&version, &sum); err != nil {
+			return nil, fmt.Errorf("migrator: failed to scan checksum: %w", err)
+		}
+		sums[version] = sum
+	}
+	return sums, rows.Err()
+}
+
+func (m *Migrator) checksum(path string) (string, error) {
+	data, err := fs.ReadFile(m.fsys, path)
+	if err != nil {
+		return "", fmt.Errorf("migrator: failed to read %s: %w", path, err)
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
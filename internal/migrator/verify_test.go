@@ -0,0 +1,128 @@
+package migrator
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"reflect"
+	"testing"
+	"testing/fstest"
+
+	"github.com/pressly/goose/v3"
+)
+
+func sha(data string) string {
+	sum := sha256.Sum256([]byte(data))
+	return hex.EncodeToString(sum[:])
+}
+
+func TestVerify(t *testing.T) {
+	fsys := fstest.MapFS{
+		"00001_init.sql":  {Data: []byte("CREATE TABLE a ();")},
+		"00002_users.sql": {Data: []byte("CREATE TABLE users ();")},
+		"00004_jobs.sql":  {Data: []byte("CREATE TABLE jobs ();")},
+	}
+	sources := []*goose.Source{
+		{Type: goose.TypeSQL, Path: "00001_init.sql", Version: 1},
+		{Type: goose.TypeSQL, Path: "00002_users.sql", Version: 2},
+		{Type: goose.TypeGo, Path: "00003_backfill.go", Version: 3},
+		{Type: goose.TypeSQL, Path: "00004_jobs.sql", Version: 4},
+	}
+	sums := map[int64]string{
+		1: sha("CREATE TABLE a ();"),
+		2: sha("CREATE TABLE users ();"),
+		4: sha("CREATE TABLE jobs ();"),
+	}
+
+	tests := []struct {
+		name     string
+		applied  []int64
+		recorded map[int64]string
+		sources  []*goose.Source
+		want     VerifyReport
+		wantOK   bool
+		wantErr  bool
+	}{
+		{
+			name:     "nothing applied",
+			recorded: map[int64]string{},
+			wantOK:   true,
+		},
+		{
+			name:     "all checksums match",
+			applied:  []int64{1, 2, 3, 4},
+			recorded: sums,
+			wantOK:   true,
+		},
+		{
+			name:     "modified file",
+			applied:  []int64{1, 2},
+			recorded: map[int64]string{1: sums[1], 2: sha("CREATE TABLE users (id INT);")},
+			want:     VerifyReport{Modified: []int64{2}},
+		},
+		{
+			name:     "checksum not recorded",
+			applied:  []int64{1, 2},
+			recorded: map[int64]string{1: sums[1]},
+			want:     VerifyReport{Unrecorded: []int64{2}},
+			wantOK:   true,
+		},
+		{
+			name:    "no checksum table",
+			applied: []int64{1},
+			want:    VerifyReport{Unrecorded: []int64{1}},
+			wantOK:  true,
+		},
+		{
+			name:     "go migrations are not checked",
+			applied:  []int64{1, 2, 3},
+			recorded: map[int64]string{1: sums[1], 2: sums[2]},
+			wantOK:   true,
+		},
+		{
+			name:     "skipped version before the latest applied",
+			applied:  []int64{1, 4},
+			recorded: sums,
+			want:     VerifyReport{Missing: []int64{2, 3}},
+		},
+		{
+			name:     "applied version without migration",
+			applied:  []int64{1, 2, 3, 4, 5},
+			recorded: sums,
+			want:     VerifyReport{Unknown: []int64{5}},
+		},
+		{
+			name:     "file missing from the filesystem",
+			applied:  []int64{1},
+			recorded: sums,
+			sources:  []*goose.Source{{Type: goose.TypeSQL, Path: "00001_gone.sql", Version: 1}},
+			wantErr:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			list := sources
+			if tt.sources != nil {
+				list = tt.sources
+			}
+
+			m := &Migrator{fsys: fsys}
+			got, err := m.verify(tt.applied, tt.recorded, list)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(*got, tt.want) {
+				t.Errorf("report = %+v, want %+v", *got, tt.want)
+			}
+			if got.OK() != tt.wantOK {
+				t.Errorf("OK = %t, want %t", got.OK(), tt.wantOK)
+			}
+		})
+	}
+}
@@ -0,0 +1,109 @@
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/desulaidovich/app/internal/postgres"
+)
+
+const notifyChannel = "outbox"
+
+var ErrNoTransaction = errors.New("outbox: event must be appended inside a transaction")
+
+// Event — доменное событие, сохранённое в таблице outbox.
+type Event struct {
+	ID        int64             `json:"id"`
+	Topic     string            `json:"topic"`
+	Key       string            `json:"key,omitempty"`
+	Payload   json.RawMessage   `json:"payload"`
+	Headers   map[string]string `json:"headers,omitempty"`
+	CreatedAt time.Time         `json:"created_at"`
+}
+
+// Publisher доставляет события во внешнюю систему. Доставка «как минимум один раз»:
+// при сбое после публикации событие может быть отправлено повторно с тем же ID.
+type Publisher interface {
+	Publish(ctx context.Context, event Event) error
+}
+
+type AppendOption func(*Event)
+
+// WithKey задаёт ключ события, например идентификатор агрегата. События с одной темой
+// и ключом публикуются в порядке фиксации транзакций.
+func WithKey(key string) AppendOption {
+	return func(e *Event) { e.Key = key }
+}
+
+func WithHeader(name, value string) AppendOption {
+	return func(e *Event) {
+		if e.Headers == nil {
+			e.Headers = make(map[string]string)
+		}
+		e.Headers[name] = value
+	}
+}
+
+// Append сохраняет событие в outbox в той же транзакции, что и изменения бизнес-данных.
+// ctx должен содержать транзакцию TxManager: событие будет опубликовано, только если
+// транзакция зафиксирована.
+//
+// id из BIGSERIAL выдаются в порядке вставки, а не фиксации, поэтому Append берёт
+// транзакционную advisory-блокировку темы и ключа события до вставки. Транзакции, пишущие
+// события с одним ключом, фиксируются по очереди, и их id следуют порядку фиксации;
+// события с разными ключами пишутся параллельно. Блокировка держится до конца транзакции,
+// поэтому Append стоит вызывать ближе к её концу, а несколько событий в одной транзакции —
+// добавлять в одном порядке ключей, иначе транзакции могут взаимно заблокироваться.
+func Append(ctx context.Context, topic string, payload any, opts ...AppendOption) (int64, error) {
+	if topic == "" {
+		return 0, errors.New("outbox: topic cannot be empty")
+	}
+	tx, ok := postgres.TxFromContext(ctx)
+	if !ok {
+		return 0, ErrNoTransaction
+	}
+
+	var event Event
+	for _, opt := range opts {
+		opt(&event)
+	}
+
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return 0, fmt.Errorf("outbox: failed to encode payload: %w", err)
+	}
+	headers, err := json.Marshal(event.Headers)
+	if err != nil {
+		return 0, fmt.Errorf("outbox: failed to encode headers: %w", err)
+	}
+	if event.Headers == nil {
+		headers = []byte("{}")
+	}
+
+	if _, err := tx.Exec(ctx, "SELECT pg_advisory_xact_lock($1)", appendLockKey(topic, event.Key)); err != nil {
+		return 0, fmt.Errorf("outbox: failed to acquire append lock: %w", err)
+	}
+
+	var id int64
+	if err := tx.QueryRow(ctx,
+		"INSERT INTO outbox (topic, key, payload, headers) VALUES ($1, $2, $3, $4) RETURNING id",
+		topic, event.Key, data, headers,
+	).Scan(&id); err != nil {
+		return 0, fmt.Errorf("outbox: failed to append event: %w", err)
+	}
+
+	if err := postgres.Notify(ctx, tx, notifyChannel, ""); err != nil {
+		return 0, err
+	}
+
+	return id, nil
+}
+
+// appendLockKey — ключ advisory-блокировки, которая упорядочивает события одной темы и ключа.
+func appendLockKey(topic, key string) int64 {
+	return postgres.AdvisoryLockKey("outbox-append:" + strconv.Quote(topic) + ":" + key)
+}
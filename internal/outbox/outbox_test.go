@@ -0,0 +1,140 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+
+	"github.com/desulaidovich/app/internal/postgres"
+)
+
+// fakeTx записывает запросы Exec и QueryRow и возвращает id для INSERT.
+type fakeTx struct {
+	pgx.Tx
+	queries []string
+	args    [][]any
+}
+
+func (tx *fakeTx) Exec(_ context.Context, sql string, args ...any) (pgconn.CommandTag, error) {
+	tx.queries = append(tx.queries, sql)
+	tx.args = append(tx.args, args)
+	return pgconn.CommandTag{}, nil
+}
+
+func (tx *fakeTx) QueryRow(_ context.Context, sql string, args ...any) pgx.Row {
+	tx.queries = append(tx.queries, sql)
+	tx.args = append(tx.args, args)
+	return idRow(42)
+}
+
+type idRow int64
+
+func (r idRow) Scan(dest ...any) error {
+	*dest[0].(*int64) = int64(r)
+	return nil
+}
+
+func TestAppendLockKey(t *testing.T) {
+	tests := []struct {
+		name       string
+		topic, key string
+		other      [2]string
+		same       bool
+	}{
+		{name: "same topic and key", topic: "orders", key: "1", other: [2]string{"orders", "1"}, same: true},
+		{name: "different key", topic: "orders", key: "1", other: [2]string{"orders", "2"}},
+		{name: "different topic", topic: "orders", key: "1", other: [2]string{"users", "1"}},
+		{name: "empty key", topic: "orders", other: [2]string{"orders", "1"}},
+		{name: "separator in topic", topic: "a:b", key: "c", other: [2]string{"a", "b:c"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, other := appendLockKey(tt.topic, tt.key), appendLockKey(tt.other[0], tt.other[1])
+			if (got == other) != tt.same {
+				t.Errorf("appendLockKey(%q, %q) = %d, appendLockKey(%q, %q) = %d, want same %t",
+					tt.topic, tt.key, got, tt.other[0], tt.other[1], other, tt.same)
+			}
+		})
+	}
+}
+
+func TestAppend(t *testing.T) {
+	tests := []struct {
+		name        string
+		noTx        bool
+		topic       string
+		payload     any
+		opts        []AppendOption
+		wantErr     error
+		wantErrText string
+		wantKey     string
+		wantHeaders string
+	}{
+		{
+			name:        "without key and headers",
+			topic:       "orders",
+			payload:     map[string]int{"id": 1},
+			wantHeaders: "{}",
+		},
+		{
+			name:        "with key and headers",
+			topic:       "orders",
+			payload:     map[string]int{"id": 1},
+			opts:        []AppendOption{WithKey("order-1"), WithHeader("trace", "abc")},
+			wantKey:     "order-1",
+			wantHeaders: `{"trace":"abc"}`,
+		},
+		{name: "no transaction", noTx: true, topic: "orders", wantErr: ErrNoTransaction},
+		{name: "empty topic", wantErrText: "topic cannot be empty"},
+		{name: "bad payload", topic: "orders", payload: func() {}, wantErrText: "failed to encode payload"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tx := &fakeTx{}
+			ctx := context.Background()
+			if !tt.noTx {
+				ctx = postgres.ContextWithTx(ctx, tx)
+			}
+
+			id, err := Append(ctx, tt.topic, tt.payload, tt.opts...)
+			if tt.wantErr != nil || tt.wantErrText != "" {
+				if err == nil || (tt.wantErr != nil && !errors.Is(err, tt.wantErr)) || !strings.Contains(err.Error(), tt.wantErrText) {
+					t.Fatalf("error = %v, want %v %q", err, tt.wantErr, tt.wantErrText)
+				}
+				if len(tx.queries) != 0 {
+					t.Errorf("queries = %q, want none", tx.queries)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if id != 42 {
+				t.Errorf("id = %d, want 42", id)
+			}
+
+			if len(tx.queries) != 3 {
+				t.Fatalf("queries = %q, want lock, insert and notify", tx.queries)
+			}
+			if !strings.Contains(tx.queries[0], "pg_advisory_xact_lock") {
+				t.Errorf("first query = %q, want the advisory lock", tx.queries[0])
+			}
+			if lock := tx.args[0][0]; lock != appendLockKey(tt.topic, tt.wantKey) {
+				t.Errorf("lock key = %v, want appendLockKey(%q, %q)", lock, tt.topic, tt.wantKey)
+			}
+			insert := tx.args[1]
+			if insert[0] != tt.topic || insert[1] != tt.wantKey || string(insert[3].([]byte)) != tt.wantHeaders {
+				t.Errorf("insert args = %v, want topic %q, key %q, headers %s", insert, tt.topic, tt.wantKey, tt.wantHeaders)
+			}
+			if !strings.Contains(tx.queries[2], "pg_notify") {
+				t.Errorf("last query = %q, want pg_notify", tx.queries[2])
+			}
+		})
+	}
+}
@@ -0,0 +1,119 @@
+package outbox
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/desulaidovich/app/pkg/log"
+)
+
+const defaultWebhookTimeout = 10 * time.Second
+
+// LogPublisher пишет события в лог. Подходит для локальной разработки.
+type LogPublisher struct {
+	logger log.Logger
+}
+
+func NewLogPublisher(logger log.Logger) (*LogPublisher, error) {
+	if logger == nil {
+		return nil, errors.New("logger cannot be nil")
+	}
+	return &LogPublisher{logger: logger}, nil
+}
+
+func (p *LogPublisher) Publish(_ context.Context, event Event) error {
+	p.logger.With(map[string]any{
+		"id":      event.ID,
+		"topic":   event.Topic,
+		"key":     event.Key,
+		"payload": string(event.Payload),
+		"headers": event.Headers,
+	}).Info("Outbox event published")
+	return nil
+}
+
+// WebhookPublisher отправляет события POST-запросом в формате JSON.
+// ID события передаётся в заголовке Idempotency-Key для дедупликации на стороне получателя.
+type WebhookPublisher struct {
+	url    string
+	client *http.Client
+}
+
+func NewWebhookPublisher(url string, client *http.Client) (*WebhookPublisher, error) {
+	if url == "" {
+		return nil, errors.New("webhook url cannot be empty")
+	}
+	if client == nil {
+		client = &http.Client{Timeout: defaultWebhookTimeout}
+	}
+	return &WebhookPublisher{url: url, client: client}, nil
+}
+
+func (p *WebhookPublisher) Publish(ctx context.Context, event Event) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to encode event: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create webhook request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Idempotency-Key", strconv.FormatInt(event.ID, 10))
+	req.Header.Set("X-Outbox-Topic", event.Topic)
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("webhook request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook responded with status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// FilePublisher дописывает события в файл по одному JSON-объекту на строку.
+type FilePublisher struct {
+	path string
+	mu   sync.Mutex
+}
+
+func NewFilePublisher(path string) (*FilePublisher, error) {
+	if path == "" {
+		return nil, errors.New("file path cannot be empty")
+	}
+	return &FilePublisher{path: path}, nil
+}
+
+func (p *FilePublisher) Publish(_ context.Context, event Event) error {
+	line, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to encode event: %w", err)
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	f, err := os.OpenFile(p.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to open outbox file: %w", err)
+	}
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		_ = f.Close()
+		return fmt.Errorf("failed to write outbox file: %w", err)
+	}
+	return f.Close()
+}
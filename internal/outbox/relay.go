@@ -0,0 +1,299 @@
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/desulaidovich/app/internal/postgres"
+	"github.com/desulaidovich/app/pkg/log"
+)
+
+const (
+	defaultPollInterval = 1 * time.Second
+	defaultBatchSize    = 100
+	defaultMaxBackoff   = 1 * time.Minute
+
+	relayLockName = "outbox-relay"
+)
+
+// Relay публикует недоставленные события из outbox в порядке id: события с одной темой
+// и ключом — в порядке фиксации транзакций (см. Append). При ошибке публикации доставка
+// останавливается на этом событии и повторяется с экспоненциальной задержкой, чтобы
+// не нарушить порядок. Одновременно работает
+// только один Relay среди всех экземпляров приложения. Реализует runner.Handler.
+type Relay struct {
+	db           *postgres.Pool
+	publisher    Publisher
+	logger       log.Logger
+	listener     *postgres.Listener
+	pollInterval time.Duration
+	batchSize    int
+	maxBackoff   time.Duration
+
+	wake   chan struct{}
+	mu     sync.Mutex
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+type RelayOption func(*Relay) error
+
+func WithLogger(logger log.Logger) RelayOption {
+	return func(r *Relay) error {
+		if logger == nil {
+			return errors.New("logger cannot be nil")
+		}
+		r.logger = logger
+		return nil
+	}
+}
+
+// WithListener позволяет начинать доставку сразу после фиксации транзакции с новым событием.
+func WithListener(listener *postgres.Listener) RelayOption {
+	return func(r *Relay) error {
+		if listener == nil {
+			return errors.New("listener cannot be nil")
+		}
+		r.listener = listener
+		return nil
+	}
+}
+
+func WithPollInterval(interval time.Duration) RelayOption {
+	return func(r *Relay) error {
+		if interval <= 0 {
+			return errors.New("pollInterval must be positive")
+		}
+		r.pollInterval = interval
+		return nil
+	}
+}
+
+func WithBatchSize(size int) RelayOption {
+	return func(r *Relay) error {
+		if size <= 0 {
+			return errors.New("batchSize must be positive")
+		}
+		r.batchSize = size
+		return nil
+	}
+}
+
+// WithMaxBackoff ограничивает задержку между повторами неудачной публикации.
+func WithMaxBackoff(backoff time.Duration) RelayOption {
+	return func(r *Relay) error {
+		if backoff <= 0 {
+			return errors.New("maxBackoff must be positive")
+		}
+		r.maxBackoff = backoff
+		return nil
+	}
+}
+
+func NewRelay(db *postgres.Pool, publisher Publisher, opts ...RelayOption) (*Relay, error) {
+	if db == nil {
+		return nil, errors.New("postgres pool cannot be nil")
+	}
+	if publisher == nil {
+		return nil, errors.New("publisher cannot be nil")
+	}
+
+	r := &Relay{
+		db:           db,
+		publisher:    publisher,
+		pollInterval: defaultPollInterval,
+		batchSize:    defaultBatchSize,
+		maxBackoff:   defaultMaxBackoff,
+		wake:         make(chan struct{}, 1),
+	}
+	for _, opt := range opts {
+		if err := opt(r); err != nil {
+			return nil, fmt.Errorf("relay option: %w", err)
+		}
+	}
+	if r.logger == nil {
+		return nil, errors.New("logger is required")
+	}
+
+	if r.listener != nil {
+		if err := r.listener.Listen(notifyChannel, func(context.Context, *pgconn.Notification) {
+			select {
+			case r.wake <- struct{}{}:
+			default:
+			}
+		}); err != nil {
+			return nil, fmt.Errorf("failed to subscribe to outbox notifications: %w", err)
+		}
+	}
+
+	return r, nil
+}
+
+func (r *Relay) Start(ctx context.Context) error {
+	r.mu.Lock()
+	if r.cancel != nil {
+		r.mu.Unlock()
+		return errors.New("relay already started")
+	}
+	ctx, r.cancel = context.WithCancel(ctx)
+	r.done = make(chan struct{})
+	r.mu.Unlock()
+
+	defer close(r.done)
+
+	var failures int
+	for {
+		delivered, err := r.deliver(ctx)
+		wait := r.pollInterval
+		switch {
+		case ctx.Err() != nil:
+			return nil
+		case err != nil:
+			failures++
+			wait = min(r.pollInterval<<min(failures, 16), r.maxBackoff)
+			r.logger.With(map[string]any{
+				"error": err.Error(),
+				"retry": wait.String(),
+			}).Warn("Outbox delivery failed")
+		default:
+			failures = 0
+		}
+
+		if delivered == r.batchSize {
+			continue
+		}
+
+		// После ошибки ждём полную задержку: новое событие всё равно не может
+		// обогнать застрявшее.
+		wake := r.wake
+		if failures > 0 {
+			wake = nil
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil
+		case <-wake:
+			timer.Stop()
+		case <-timer.C:
+		}
+	}
+}
+
+func (r *Relay) Stop(ctx context.Context) error {
+	r.mu.Lock()
+	cancel, done := r.cancel, r.done
+	r.mu.Unlock()
+
+	if cancel == nil {
+		return nil
+	}
+	cancel()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("relay stop: %w", ctx.Err())
+	}
+}
+
+// deliver публикует очередную пачку событий под сессионной advisory-блокировкой.
+// Возвращает число доставленных событий.
+func (r *Relay) deliver(ctx context.Context) (int, error) {
+	conn, err := r.db.Acquire(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to acquire connection: %w", err)
+	}
+	defer conn.Release()
+
+	key := postgres.AdvisoryLockKey(relayLockName)
+	var locked bool
+	if err := conn.QueryRow(ctx, "SELECT pg_try_advisory_lock($1)", key).Scan(&locked); err != nil {
+		return 0, fmt.Errorf("failed to acquire relay lock: %w", err)
+	}
+	if !locked {
+		return 0, nil
+	}
+	defer func() {
+		_, _ = conn.Exec(context.WithoutCancel(ctx), "SELECT pg_advisory_unlock($1)", key)
+	}()
+
+	events, err := r.pending(ctx, conn)
+	if err != nil {
+		return 0, err
+	}
+
+	for i, event := range events {
+		if err := r.publisher.Publish(ctx, event); err != nil {
+			if _, updErr := conn.Exec(context.WithoutCancel(ctx),
+				"UPDATE outbox SET attempts = attempts + 1, last_error = $2 WHERE id = $1",
+				event.ID, err.Error(),
+			); updErr != nil {
+				err = errors.Join(err, updErr)
+			}
+			return i, fmt.Errorf("failed to publish event %d: %w", event.ID, err)
+		}
+
+		if _, err := conn.Exec(context.WithoutCancel(ctx),
+			"UPDATE outbox SET attempts = attempts + 1, last_error = NULL, delivered_at = now() WHERE id = $1",
+			event.ID,
+		); err != nil {
+			return i, fmt.Errorf("failed to mark event %d delivered: %w", event.ID, err)
+		}
+	}
+
+	return len(events), nil
+}
+
+func (r *Relay) pending(ctx context.Context, conn *pgxpool.Conn) ([]Event, error) {
+	rows, err := conn.Query(ctx, `
+		SELECT id, topic, key, payload, headers, created_at
+		FROM outbox
+		WHERE delivered_at IS NULL
+		ORDER BY id
+		LIMIT $1`, r.batchSize)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load outbox events: %w", err)
+	}
+	defer rows.Close()
+
+	var events []Event
+	for rows.Next() {
+		var (
+			event   Event
+			headers []byte
+		)
+		if err := rows.Scan(&event.ID, &event.Topic, &event.Key, &event.Payload, &headers, &event.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan outbox event: %w", err)
+		}
+		if err := json.Unmarshal(headers, &event.Headers); err != nil {
+			return nil, fmt.Errorf("failed to decode headers of event %d: %w", event.ID, err)
+		}
+		events = append(events, event)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to load outbox events: %w", err)
+	}
+
+	return events, nil
+}
+
+// Purge удаляет события, доставленные раньше olderThan назад. Возвращает число удалённых событий.
+func (r *Relay) Purge(ctx context.Context, olderThan time.Duration) (int64, error) {
+	tag, err := r.db.Exec(ctx,
+		"DELETE FROM outbox WHERE delivered_at < now() - $1::interval", olderThan)
+	if err != nil {
+		return 0, fmt.Errorf("failed to purge outbox: %w", err)
+	}
+	return tag.RowsAffected(), nil
+}
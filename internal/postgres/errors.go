@@ -0,0 +1,114 @@
+package postgres
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+const (
+	codeNotNullViolation    = "23502"
+	codeForeignKeyViolation = "23503"
+	codeUniqueViolation     = "23505"
+	codeCheckViolation      = "23514"
+)
+
+var (
+	ErrNotFound       = errors.New("record not found")
+	ErrAlreadyExists  = errors.New("record already exists")
+	ErrForeignKey     = errors.New("referenced record does not exist or is still referenced")
+	ErrNotNull        = errors.New("required value is missing")
+	ErrCheckViolation = errors.New("value violates check constraint")
+)
+
+var (
+	constraintSuffixes  = []string{"_pkey", "_fkey", "_key", "_check", "_excl", "_idx", "_not_null"}
+	constraintKindNames = map[error]string{
+		ErrAlreadyExists:  "unique",
+		ErrForeignKey:     "foreign_key",
+		ErrNotNull:        "not_null",
+		ErrCheckViolation: "check",
+	}
+)
+
+// Error — классифицированная ошибка PostgreSQL. Kind — одна из ошибок Err*,
+// errors.Is работает как с Kind, так и с исходной ошибкой драйвера.
+type Error struct {
+	Kind       error
+	Table      string
+	Column     string
+	Constraint string
+	Err        error
+}
+
+func (e *Error) Error() string {
+	if e.Constraint != "" {
+		return e.Kind.Error() + " (" + constraintKindNames[e.Kind] + " constraint " + e.Constraint + ")"
+	}
+	return e.Kind.Error()
+}
+
+func (e *Error) Unwrap() []error {
+	return []error{e.Kind, e.Err}
+}
+
+// Field возвращает имя поля, к которому относится ошибка: колонку, если её сообщил сервер,
+// иначе имя, выведенное из имени ограничения по соглашению <table>_<field>_<suffix>.
+// Для ограничения без колонки в имени, например <table>_pkey, возвращается пустая строка.
+func (e *Error) Field() string {
+	if e.Column != "" {
+		return e.Column
+	}
+
+	field := e.Constraint
+	for _, suffix := range constraintSuffixes {
+		if trimmed, ok := strings.CutSuffix(field, suffix); ok {
+			field = trimmed
+			break
+		}
+	}
+	if field == e.Table {
+		return ""
+	}
+	return strings.TrimPrefix(field, e.Table+"_")
+}
+
+// Classify превращает ошибки pgx в *Error. Остальные ошибки возвращаются без изменений.
+func Classify(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	if errors.Is(err, pgx.ErrNoRows) {
+		return &Error{Kind: ErrNotFound, Err: err}
+	}
+
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) {
+		return err
+	}
+
+	var kind error
+	switch pgErr.Code {
+	case codeUniqueViolation:
+		kind = ErrAlreadyExists
+	case codeForeignKeyViolation:
+		kind = ErrForeignKey
+	case codeNotNullViolation:
+		kind = ErrNotNull
+	case codeCheckViolation:
+		kind = ErrCheckViolation
+	default:
+		return err
+	}
+
+	return &Error{
+		Kind:       kind,
+		Table:      pgErr.TableName,
+		Column:     pgErr.ColumnName,
+		Constraint: pgErr.ConstraintName,
+		Err:        err,
+	}
+}
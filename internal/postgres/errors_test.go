@@ -0,0 +1,117 @@
+package postgres
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+func TestClassify(t *testing.T) {
+	other := errors.New("connection reset")
+
+	tests := []struct {
+		name     string
+		err      error
+		wantKind error
+		want     error
+		wantText string
+	}{
+		{name: "nil", err: nil, want: nil},
+		{name: "no rows", err: pgx.ErrNoRows, wantKind: ErrNotFound, wantText: "record not found"},
+		{
+			name:     "wrapped no rows",
+			err:      fmt.Errorf("get user: %w", pgx.ErrNoRows),
+			wantKind: ErrNotFound,
+			wantText: "record not found",
+		},
+		{
+			name:     "unique violation",
+			err:      &pgconn.PgError{Code: codeUniqueViolation, TableName: "users", ConstraintName: "users_email_key"},
+			wantKind: ErrAlreadyExists,
+			wantText: "record already exists (unique constraint users_email_key)",
+		},
+		{
+			name:     "foreign key violation",
+			err:      &pgconn.PgError{Code: codeForeignKeyViolation, TableName: "orders", ConstraintName: "orders_user_id_fkey"},
+			wantKind: ErrForeignKey,
+			wantText: "referenced record does not exist or is still referenced (foreign_key constraint orders_user_id_fkey)",
+		},
+		{
+			name:     "not null violation",
+			err:      &pgconn.PgError{Code: codeNotNullViolation, TableName: "users", ColumnName: "email"},
+			wantKind: ErrNotNull,
+			wantText: "required value is missing",
+		},
+		{
+			name:     "check violation",
+			err:      &pgconn.PgError{Code: codeCheckViolation, TableName: "jobs", ConstraintName: "jobs_attempts_check"},
+			wantKind: ErrCheckViolation,
+			wantText: "value violates check constraint (check constraint jobs_attempts_check)",
+		},
+		{name: "other pg error", err: &pgconn.PgError{Code: "40001"}},
+		{name: "non pg error", err: other, want: other},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Classify(tt.err)
+
+			if tt.wantKind == nil {
+				var pgErr *Error
+				if errors.As(got, &pgErr) {
+					t.Fatalf("Classify = %v, want an unclassified error", got)
+				}
+				if tt.want != nil && got != tt.want {
+					t.Errorf("Classify = %v, want %v", got, tt.want)
+				}
+				if tt.err != nil && !errors.Is(got, tt.err) {
+					t.Errorf("Classify = %v, want the original error", got)
+				}
+				return
+			}
+
+			if !errors.Is(got, tt.wantKind) {
+				t.Errorf("errors.Is(%v, %v) = false", got, tt.wantKind)
+			}
+			if !errors.Is(got, tt.err) {
+				t.Errorf("Classify = %v does not wrap the driver error", got)
+			}
+			if got.Error() != tt.wantText {
+				t.Errorf("Error() = %q, want %q", got.Error(), tt.wantText)
+			}
+		})
+	}
+}
+
+func TestErrorField(t *testing.T) {
+	tests := []struct {
+		name string
+		err  Error
+		want string
+	}{
+		{
+			name: "column reported by the server",
+			err:  Error{Table: "users", Column: "email", Constraint: "users_email_not_null"},
+			want: "email",
+		},
+		{name: "unique key", err: Error{Table: "users", Constraint: "users_email_key"}, want: "email"},
+		{name: "foreign key", err: Error{Table: "orders", Constraint: "orders_user_id_fkey"}, want: "user_id"},
+		{name: "check", err: Error{Table: "jobs", Constraint: "jobs_max_attempts_check"}, want: "max_attempts"},
+		{name: "unique index", err: Error{Table: "jobs", Constraint: "jobs_unique_key_idx"}, want: "unique_key"},
+		{name: "exclusion", err: Error{Table: "bookings", Constraint: "bookings_period_excl"}, want: "period"},
+		{name: "primary key", err: Error{Table: "users", Constraint: "users_pkey"}, want: ""},
+		{name: "custom constraint name", err: Error{Table: "users", Constraint: "email_format"}, want: "email_format"},
+		{name: "no constraint", err: Error{Table: "users"}, want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.err.Field(); got != tt.want {
+				t.Errorf("Field() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
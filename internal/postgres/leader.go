@@ -0,0 +1,363 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/desulaidovich/app/pkg/log"
+	"github.com/desulaidovich/app/pkg/runner"
+)
+
+const (
+	defaultElectorRetryPeriod = 5 * time.Second
+	defaultElectorCheckPeriod = 2 * time.Second
+	defaultElectorStopTimeout = 10 * time.Second
+)
+
+// advisoryLockHeldQuery проверяет, что соединение держит advisory-блокировку. Ключ bigint
+// хранится в pg_locks двумя oid: старшие 32 бита в classid, младшие в objid (см. lockKeyParts).
+const advisoryLockHeldQuery = `
+SELECT EXISTS (
+    SELECT 1 FROM pg_locks
+    WHERE locktype = 'advisory'
+      AND pid = pg_backend_pid()
+      AND granted
+      AND classid = $1::bigint::oid
+      AND objid = $2::bigint::oid
+      AND objsubid = 1
+)`
+
+// Elector выбирает лидера среди экземпляров приложения с помощью сессионной
+// advisory-блокировки на выделенном соединении. Пока экземпляр является лидером,
+// запущены его leader-only обработчики. Реализует runner.Handler.
+type Elector struct {
+	connConfig  *pgx.ConnConfig
+	name        string
+	key         int64
+	logger      log.Logger
+	retryPeriod time.Duration
+	checkPeriod time.Duration
+	stopTimeout time.Duration
+	onElected   func(ctx context.Context)
+	onRevoked   func(ctx context.Context)
+	factories   []func() (runner.Handler, error)
+
+	leader atomic.Bool
+	mu     sync.Mutex
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+type ElectorOption func(*Elector) error
+
+func WithElectorLogger(logger log.Logger) ElectorOption {
+	return func(e *Elector) error {
+		if logger == nil {
+			return errors.New("logger cannot be nil")
+		}
+		e.logger = logger
+		return nil
+	}
+}
+
+// WithElectorRetryPeriod задаёт период попыток захватить лидерство.
+func WithElectorRetryPeriod(period time.Duration) ElectorOption {
+	return func(e *Elector) error {
+		if period <= 0 {
+			return errors.New("retryPeriod must be positive")
+		}
+		e.retryPeriod = period
+		return nil
+	}
+}
+
+// WithElectorCheckPeriod задаёт период проверки, что блокировка всё ещё удерживается.
+// Это верхняя граница времени, в течение которого потерявший соединение экземпляр
+// продолжает считать себя лидером.
+func WithElectorCheckPeriod(period time.Duration) ElectorOption {
+	return func(e *Elector) error {
+		if period <= 0 {
+			return errors.New("checkPeriod must be positive")
+		}
+		e.checkPeriod = period
+		return nil
+	}
+}
+
+// WithElectorStopTimeout задаёт время ожидания остановки leader-only обработчиков при потере лидерства.
+func WithElectorStopTimeout(timeout time.Duration) ElectorOption {
+	return func(e *Elector) error {
+		if timeout <= 0 {
+			return errors.New("stopTimeout must be positive")
+		}
+		e.stopTimeout = timeout
+		return nil
+	}
+}
+
+// WithElectorCallbacks задаёт функции, вызываемые при получении и потере лидерства.
+// Любая из них может быть nil.
+func WithElectorCallbacks(onElected, onRevoked func(ctx context.Context)) ElectorOption {
+	return func(e *Elector) error {
+		e.onElected = onElected
+		e.onRevoked = onRevoked
+		return nil
+	}
+}
+
+// WithLeaderHandler добавляет обработчик, который работает только на лидере. На каждый срок
+// лидерства newHandler создаёт новый обработчик, который останавливается при потере лидерства:
+// обработчики в этом репозитории не запускаются повторно после Stop.
+func WithLeaderHandler(newHandler func() (runner.Handler, error)) ElectorOption {
+	return func(e *Elector) error {
+		if newHandler == nil {
+			return errors.New("leader handler factory cannot be nil")
+		}
+		e.factories = append(e.factories, newHandler)
+		return nil
+	}
+}
+
+// NewElector создаёт участника выборов. Экземпляры с одинаковым name конкурируют за одну блокировку.
+func NewElector(pool *Pool, name string, opts ...ElectorOption) (*Elector, error) {
+	if pool == nil {
+		return nil, errors.New("postgres pool cannot be nil")
+	}
+	if name == "" {
+		return nil, errors.New("election name cannot be empty")
+	}
+
+	e := &Elector{
+		connConfig:  pool.Config().ConnConfig,
+		name:        name,
+		key:         AdvisoryLockKey(name),
+		retryPeriod: defaultElectorRetryPeriod,
+		checkPeriod: defaultElectorCheckPeriod,
+		stopTimeout: defaultElectorStopTimeout,
+	}
+	for _, opt := range opts {
+		if err := opt(e); err != nil {
+			return nil, fmt.Errorf("elector option: %w", err)
+		}
+	}
+
+	return e, nil
+}
+
+// AdvisoryLockKey возвращает ключ advisory-блокировки для имени.
+func AdvisoryLockKey(name string) int64 {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(name))
+	return int64(h.Sum64())
+}
+
+// lockKeyParts делит ключ advisory-блокировки на classid и objid так же, как pg_locks.
+func lockKeyParts(key int64) (classid, objid int64) {
+	return int64(uint64(key) >> 32), int64(uint64(key) & 0xFFFFFFFF)
+}
+
+// IsLeader сообщает, является ли экземпляр лидером в данный момент.
+func (e *Elector) IsLeader() bool {
+	return e.leader.Load()
+}
+
+func (e *Elector) Start(ctx context.Context) error {
+	e.mu.Lock()
+	if e.cancel != nil {
+		e.mu.Unlock()
+		return errors.New("elector already started")
+	}
+	ctx, e.cancel = context.WithCancel(ctx)
+	e.done = make(chan struct{})
+	e.mu.Unlock()
+
+	defer close(e.done)
+
+	for {
+		if err := e.campaign(ctx); err != nil && ctx.Err() == nil && e.logger != nil {
+			e.logger.With(map[string]any{
+				"election": e.name,
+				"error":    err.Error(),
+			}).Warn("Leader election failed")
+		}
+
+		timer := time.NewTimer(e.retryPeriod)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil
+		case <-timer.C:
+		}
+	}
+}
+
+func (e *Elector) Stop(ctx context.Context) error {
+	e.mu.Lock()
+	cancel, done := e.cancel, e.done
+	e.mu.Unlock()
+
+	if cancel == nil {
+		return nil
+	}
+	cancel()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("elector stop: %w", ctx.Err())
+	}
+}
+
+// campaign пытается захватить блокировку на новом соединении и удерживает
+// лидерство, пока соединение живо и блокировка принадлежит этой сессии.
+func (e *Elector) campaign(ctx context.Context) error {
+	conn, err := pgx.ConnectConfig(ctx, e.connConfig)
+	if err != nil {
+		return fmt.Errorf("failed to connect: %w", err)
+	}
+	defer conn.Close(context.WithoutCancel(ctx))
+
+	for {
+		var acquired bool
+		if err := conn.QueryRow(ctx, "SELECT pg_try_advisory_lock($1)", e.key).Scan(&acquired); err != nil {
+			return fmt.Errorf("failed to acquire lock: %w", err)
+		}
+		if acquired {
+			break
+		}
+
+		timer := time.NewTimer(e.retryPeriod)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+
+	leaderCtx, revoke := context.WithCancel(ctx)
+	defer revoke()
+
+	handlers, wait := e.elect(leaderCtx)
+	err = e.hold(leaderCtx, conn)
+	revoke()
+	e.revoke(ctx, handlers, wait)
+
+	if ctx.Err() == nil {
+		return err
+	}
+
+	unlockCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), e.checkPeriod)
+	defer cancel()
+	if _, unlockErr := conn.Exec(unlockCtx, "SELECT pg_advisory_unlock($1)", e.key); unlockErr != nil {
+		return fmt.Errorf("failed to release lock: %w", unlockErr)
+	}
+	return nil
+}
+
+func (e *Elector) hold(ctx context.Context, conn *pgx.Conn) error {
+	ticker := time.NewTicker(e.checkPeriod)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+
+		checkCtx, cancel := context.WithTimeout(ctx, e.checkPeriod)
+		var held bool
+		classid, objid := lockKeyParts(e.key)
+		err := conn.QueryRow(checkCtx, advisoryLockHeldQuery, classid, objid).Scan(&held)
+		cancel()
+		if ctx.Err() != nil {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("lease check failed: %w", err)
+		}
+		if !held {
+			return errors.New("advisory lock is no longer held")
+		}
+	}
+}
+
+// elect отмечает экземпляр лидером, создаёт и запускает leader-only обработчики.
+// Возвращает созданные обработчики и функцию ожидания завершения их Start.
+func (e *Elector) elect(ctx context.Context) (handlers []runner.Handler, wait func()) {
+	e.leader.Store(true)
+	if e.logger != nil {
+		e.logger.With(map[string]any{
+			"election": e.name,
+		}).Info("Leadership acquired")
+	}
+
+	var wg sync.WaitGroup
+	for _, newHandler := range e.factories {
+		handler, err := newHandler()
+		if err == nil && handler == nil {
+			err = errors.New("leader handler factory returned nil")
+		}
+		if err != nil {
+			if e.logger != nil {
+				e.logger.With(map[string]any{
+					"election": e.name,
+					"error":    err.Error(),
+				}).Error("Failed to create leader handler")
+			}
+			continue
+		}
+		handlers = append(handlers, handler)
+
+		wg.Go(func() {
+			if err := handler.Start(ctx); err != nil && e.logger != nil {
+				e.logger.With(map[string]any{
+					"election": e.name,
+					"error":    err.Error(),
+				}).Error("Leader handler failed")
+			}
+		})
+	}
+
+	if e.onElected != nil {
+		e.onElected(ctx)
+	}
+
+	return handlers, wg.Wait
+}
+
+func (e *Elector) revoke(ctx context.Context, handlers []runner.Handler, wait func()) {
+	e.leader.Store(false)
+
+	stopCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), e.stopTimeout)
+	defer cancel()
+
+	for _, handler := range slices.Backward(handlers) {
+		if err := handler.Stop(stopCtx); err != nil && e.logger != nil {
+			e.logger.With(map[string]any{
+				"election": e.name,
+				"error":    err.Error(),
+			}).Error("Failed to stop leader handler")
+		}
+	}
+	wait()
+
+	if e.onRevoked != nil {
+		e.onRevoked(stopCtx)
+	}
+	if e.logger != nil {
+		e.logger.With(map[string]any{
+			"election": e.name,
+		}).Info("Leadership released")
+	}
+}
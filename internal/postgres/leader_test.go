@@ -0,0 +1,83 @@
+package postgres
+
+import (
+	"context"
+	"math"
+	"os"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+)
+
+func TestLockKeyParts(t *testing.T) {
+	tests := []struct {
+		key         int64
+		wantClassid int64
+		wantObjid   int64
+	}{
+		{key: 0},
+		{key: 1, wantObjid: 1},
+		{key: 1 << 32, wantClassid: 1},
+		{key: 0x12345678_9ABCDEF0, wantClassid: 0x12345678, wantObjid: 0x9ABCDEF0},
+		{key: -1, wantClassid: 0xFFFFFFFF, wantObjid: 0xFFFFFFFF},
+		{key: math.MinInt64, wantClassid: 0x80000000},
+		{key: math.MaxInt64, wantClassid: 0x7FFFFFFF, wantObjid: 0xFFFFFFFF},
+	}
+
+	for _, tt := range tests {
+		classid, objid := lockKeyParts(tt.key)
+		if classid != tt.wantClassid || objid != tt.wantObjid {
+			t.Errorf("lockKeyParts(%d) = %#x, %#x, want %#x, %#x", tt.key, classid, objid, tt.wantClassid, tt.wantObjid)
+		}
+		if int64(uint64(classid)<<32|uint64(objid)) != tt.key {
+			t.Errorf("lockKeyParts(%d) does not round-trip", tt.key)
+		}
+	}
+}
+
+// TestAdvisoryLockHeldQuery проверяет запрос продления лидерства на настоящем pg_locks.
+// Без TEST_DATABASE_DSN тест пропускается.
+func TestAdvisoryLockHeldQuery(t *testing.T) {
+	dsn := os.Getenv("TEST_DATABASE_DSN")
+	if dsn == "" {
+		t.Skip("TEST_DATABASE_DSN is not set")
+	}
+
+	ctx := context.Background()
+	conn, err := pgx.Connect(ctx, dsn)
+	if err != nil {
+		t.Fatalf("failed to connect: %v", err)
+	}
+	t.Cleanup(func() { _ = conn.Close(context.Background()) })
+
+	held := func(key int64) bool {
+		t.Helper()
+		var held bool
+		classid, objid := lockKeyParts(key)
+		if err := conn.QueryRow(ctx, advisoryLockHeldQuery, classid, objid).Scan(&held); err != nil {
+			t.Fatalf("lease query for %d: %v", key, err)
+		}
+		return held
+	}
+
+	for _, key := range []int64{AdvisoryLockKey("leader"), 1, -1, math.MinInt64, math.MaxInt64} {
+		if held(key) {
+			t.Fatalf("key %d is held before locking", key)
+		}
+		if _, err := conn.Exec(ctx, "SELECT pg_advisory_lock($1)", key); err != nil {
+			t.Fatal(err)
+		}
+		if !held(key) {
+			t.Errorf("key %d is not reported as held", key)
+		}
+		if held(key ^ 1) {
+			t.Errorf("key %d is reported as held while %d is locked", key^1, key)
+		}
+		if _, err := conn.Exec(ctx, "SELECT pg_advisory_unlock($1)", key); err != nil {
+			t.Fatal(err)
+		}
+		if held(key) {
+			t.Errorf("key %d is reported as held after unlock", key)
+		}
+	}
+}
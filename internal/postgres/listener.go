@@ -0,0 +1,226 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+
+	"github.com/desulaidovich/app/pkg/log"
+)
+
+const (
+	defaultListenerMinBackoff = 500 * time.Millisecond
+	defaultListenerMaxBackoff = 30 * time.Second
+)
+
+// NotificationHandler обрабатывает уведомление NOTIFY. Вызывается последовательно
+// в горутине слушателя, поэтому долгую работу стоит выносить в отдельную горутину.
+type NotificationHandler func(ctx context.Context, n *pgconn.Notification)
+
+// Listener держит выделенное соединение вне пула и доставляет уведомления LISTEN/NOTIFY.
+// После потери соединения переподключается и заново подписывается на все каналы.
+// Реализует runner.Handler.
+type Listener struct {
+	connConfig  *pgx.ConnConfig
+	logger      log.Logger
+	minBackoff  time.Duration
+	maxBackoff  time.Duration
+	onReconnect func(ctx context.Context)
+
+	mu       sync.Mutex
+	handlers map[string][]NotificationHandler
+	started  bool
+	cancel   context.CancelFunc
+	done     chan struct{}
+}
+
+type ListenerOption func(*Listener) error
+
+func WithListenerLogger(logger log.Logger) ListenerOption {
+	return func(l *Listener) error {
+		if logger == nil {
+			return errors.New("logger cannot be nil")
+		}
+		l.logger = logger
+		return nil
+	}
+}
+
+// WithListenerBackoff задаёт начальную и максимальную задержку между попытками переподключения.
+func WithListenerBackoff(initial, maximum time.Duration) ListenerOption {
+	return func(l *Listener) error {
+		if initial <= 0 {
+			return errors.New("initial backoff must be positive")
+		}
+		if maximum < initial {
+			return errors.New("max backoff cannot be less than initial backoff")
+		}
+		l.minBackoff = initial
+		l.maxBackoff = maximum
+		return nil
+	}
+}
+
+// WithListenerReconnectHook задаёт функцию, вызываемую после переподключения.
+// Уведомления, отправленные пока соединения не было, теряются, и hook позволяет,
+// например, сбросить кеш целиком.
+func WithListenerReconnectHook(hook func(ctx context.Context)) ListenerOption {
+	return func(l *Listener) error {
+		if hook == nil {
+			return errors.New("reconnect hook cannot be nil")
+		}
+		l.onReconnect = hook
+		return nil
+	}
+}
+
+func NewListener(pool *Pool, opts ...ListenerOption) (*Listener, error) {
+	if pool == nil {
+		return nil, errors.New("postgres pool cannot be nil")
+	}
+
+	l := &Listener{
+		connConfig: pool.Config().ConnConfig,
+		minBackoff: defaultListenerMinBackoff,
+		maxBackoff: defaultListenerMaxBackoff,
+		handlers:   make(map[string][]NotificationHandler),
+	}
+	for _, opt := range opts {
+		if err := opt(l); err != nil {
+			return nil, fmt.Errorf("listener option: %w", err)
+		}
+	}
+
+	return l, nil
+}
+
+// Listen подписывает handler на канал. Подписки регистрируются до вызова Start.
+func (l *Listener) Listen(channel string, handler NotificationHandler) error {
+	if channel == "" {
+		return errors.New("channel cannot be empty")
+	}
+	if handler == nil {
+		return errors.New("notification handler cannot be nil")
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.started {
+		return errors.New("listener already started")
+	}
+	l.handlers[channel] = append(l.handlers[channel], handler)
+	return nil
+}
+
+// Notify отправляет уведомление в канал. Внутри транзакции уведомление
+// будет доставлено только после её фиксации.
+func Notify(ctx context.Context, q Querier, channel, payload string) error {
+	if _, err := q.Exec(ctx, "SELECT pg_notify($1, $2)", channel, payload); err != nil {
+		return fmt.Errorf("failed to notify %s: %w", channel, err)
+	}
+	return nil
+}
+
+func (l *Listener) Start(ctx context.Context) error {
+	l.mu.Lock()
+	if l.started {
+		l.mu.Unlock()
+		return errors.New("listener already started")
+	}
+	l.started = true
+	ctx, l.cancel = context.WithCancel(ctx)
+	l.done = make(chan struct{})
+	l.mu.Unlock()
+
+	defer close(l.done)
+
+	if len(l.handlers) == 0 {
+		<-ctx.Done()
+		return nil
+	}
+
+	backoff := l.minBackoff
+	for attempt := 0; ; attempt++ {
+		connected, err := l.listen(ctx, attempt > 0)
+		if ctx.Err() != nil {
+			return nil
+		}
+		if connected {
+			backoff = l.minBackoff
+		}
+		if l.logger != nil {
+			l.logger.With(map[string]any{
+				"error": err.Error(),
+				"retry": backoff.String(),
+			}).Warn("Listener connection lost")
+		}
+
+		timer := time.NewTimer(backoff)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil
+		case <-timer.C:
+		}
+		backoff = min(backoff*2, l.maxBackoff)
+	}
+}
+
+func (l *Listener) Stop(ctx context.Context) error {
+	l.mu.Lock()
+	cancel, done := l.cancel, l.done
+	l.mu.Unlock()
+
+	if cancel == nil {
+		return nil
+	}
+	cancel()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("listener stop: %w", ctx.Err())
+	}
+}
+
+// listen подключается, подписывается на каналы и доставляет уведомления до ошибки соединения.
+// connected сообщает, удалось ли подписаться, чтобы сбросить задержку переподключения.
+func (l *Listener) listen(ctx context.Context, reconnect bool) (connected bool, err error) {
+	conn, err := pgx.ConnectConfig(ctx, l.connConfig)
+	if err != nil {
+		return false, fmt.Errorf("failed to connect: %w", err)
+	}
+	defer conn.Close(context.WithoutCancel(ctx))
+
+	for channel := range l.handlers {
+		if _, err := conn.Exec(ctx, "LISTEN "+pgx.Identifier{channel}.Sanitize()); err != nil {
+			return false, fmt.Errorf("failed to listen %s: %w", channel, err)
+		}
+	}
+
+	if l.logger != nil {
+		l.logger.With(map[string]any{
+			"channels": len(l.handlers),
+		}).Info("Listener connected")
+	}
+	if reconnect && l.onReconnect != nil {
+		l.onReconnect(ctx)
+	}
+
+	for {
+		n, err := conn.WaitForNotification(ctx)
+		if err != nil {
+			return true, err
+		}
+		for _, handler := range l.handlers[n.Channel] {
+			handler(ctx, n)
+		}
+	}
+}
@@ -5,9 +5,14 @@ import (
 	"errors"
 	"fmt"
 	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/desulaidovich/app/pkg/log"
 )
 
 const (
@@ -17,21 +22,32 @@ const (
 	defaultMaxConnIdleTime   = 5 * time.Minute
 	defaultHealthCheckPeriod = 1 * time.Minute
 	defaultConnectTimeout    = 5 * time.Second
+	defaultSlowQuery         = 500 * time.Millisecond
 )
 
 type Pool struct {
 	*pgxpool.Pool
-	config *Config
+	config   *Config
+	replicas []*replica
+	next     atomic.Uint64
+	stop     context.CancelFunc
+	wg       sync.WaitGroup
 }
 
 type Config struct {
 	DSN               string
+	SSLMode           string
 	MaxConns          int32
 	MinConns          int32
 	MaxConnLifetime   time.Duration
 	MaxConnIdleTime   time.Duration
 	HealthCheckPeriod time.Duration
 	ConnectTimeout    time.Duration
+	Logger            log.Logger
+	SlowQuery         time.Duration
+	LogQueries        bool
+	LogQueryArgs      bool
+	Replicas          ReplicaConfig
 }
 
 type Option func(*Config) error
@@ -96,6 +112,46 @@ func WithConnectTimeout(timeout time.Duration) Option {
 	}
 }
 
+// WithLogger включает логирование медленных запросов через logger.
+func WithLogger(logger log.Logger) Option {
+	return func(c *Config) error {
+		if logger == nil {
+			return errors.New("logger cannot be nil")
+		}
+		c.Logger = logger
+		return nil
+	}
+}
+
+// WithSlowQueryThreshold задаёт длительность, начиная с которой запрос считается медленным.
+func WithSlowQueryThreshold(threshold time.Duration) Option {
+	return func(c *Config) error {
+		if threshold <= 0 {
+			return errors.New("slowQueryThreshold must be positive")
+		}
+		c.SlowQuery = threshold
+		return nil
+	}
+}
+
+// WithQueryLogging включает логирование всех запросов на уровне DEBUG.
+func WithQueryLogging(enabled bool) Option {
+	return func(c *Config) error {
+		c.LogQueries = enabled
+		return nil
+	}
+}
+
+// WithQueryArgsLogging включает вывод значений параметров запросов в лог. По умолчанию
+// выводятся только тип и длина параметров: значения могут содержать пароли, токены
+// и персональные данные.
+func WithQueryArgsLogging(enabled bool) Option {
+	return func(c *Config) error {
+		c.LogQueryArgs = enabled
+		return nil
+	}
+}
+
 func WithDSN(dsn string) Option {
 	return func(c *Config) error {
 		if dsn == "" {
@@ -106,12 +162,16 @@ func WithDSN(dsn string) Option {
 	}
 }
 
+// SSLModes — режимы SSL, которые принимает WithSSLMode. Тег oneof поля
+// DATABASE_SSL_MODE в config.Config должен совпадать с этим списком.
+var SSLModes = []string{"disable", "require", "verify-ca", "verify-full"}
+
 func WithSSLMode(mode string) Option {
 	return func(c *Config) error {
-		if !slices.Contains([]string{"disable", "require", "verify-ca", "verify-full"}, mode) {
-			return fmt.Errorf("unsupported SSL mode: %s", mode)
+		if !slices.Contains(SSLModes, mode) {
+			return fmt.Errorf("unsupported SSL mode: %s (valid: %s)", mode, strings.Join(SSLModes, ", "))
 		}
-		c.DSN += "&sslmode=" + mode
+		c.SSLMode = mode
 		return nil
 	}
 }
@@ -124,6 +184,12 @@ func New(ctx context.Context, opts ...Option) (*Pool, error) {
 		MaxConnIdleTime:   defaultMaxConnIdleTime,
 		HealthCheckPeriod: defaultHealthCheckPeriod,
 		ConnectTimeout:    defaultConnectTimeout,
+		SlowQuery:         defaultSlowQuery,
+		Replicas: ReplicaConfig{
+			Strategy:    RoundRobin,
+			MaxLag:      defaultMaxReplicationLag,
+			CheckPeriod: defaultReplicaCheckPeriod,
+		},
 	}
 
 	for _, opt := range opts {
@@ -136,7 +202,35 @@ func New(ctx context.Context, opts ...Option) (*Pool, error) {
 		return nil, errors.New("DSN is required: use WithDSN option")
 	}
 
-	pgxCfg, err := pgxpool.ParseConfig(cfg.DSN)
+	pool, err := newPool(ctx, cfg.DSN, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := pool.Ping(ctx); err != nil {
+		pool.Close()
+		return nil, fmt.Errorf("failed to ping database: %w", err)
+	}
+
+	p := &Pool{Pool: pool, config: cfg}
+	if err := p.startReplicas(ctx); err != nil {
+		p.Close()
+		return nil, err
+	}
+
+	return p, nil
+}
+
+func newPool(ctx context.Context, dsn string, cfg *Config) (*pgxpool.Pool, error) {
+	if cfg.SSLMode != "" {
+		sep := "?"
+		if strings.Contains(dsn, "?") {
+			sep = "&"
+		}
+		dsn += sep + "sslmode=" + cfg.SSLMode
+	}
+
+	pgxCfg, err := pgxpool.ParseConfig(dsn)
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse DSN: %w", err)
 	}
@@ -147,21 +241,31 @@ func New(ctx context.Context, opts ...Option) (*Pool, error) {
 	pgxCfg.MaxConnIdleTime = cfg.MaxConnIdleTime
 	pgxCfg.HealthCheckPeriod = cfg.HealthCheckPeriod
 	pgxCfg.ConnConfig.ConnectTimeout = cfg.ConnectTimeout
+	if cfg.Logger != nil {
+		pgxCfg.ConnConfig.Tracer = &tracer{
+			logger:    cfg.Logger,
+			threshold: cfg.SlowQuery,
+			logAll:    cfg.LogQueries,
+			logArgs:   cfg.LogQueryArgs,
+		}
+	}
 
 	pool, err := pgxpool.NewWithConfig(ctx, pgxCfg)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create pool: %w", err)
 	}
 
-	if err := pool.Ping(ctx); err != nil {
-		pool.Close()
-		return nil, fmt.Errorf("failed to ping database: %w", err)
-	}
-
-	return &Pool{Pool: pool, config: cfg}, nil
+	return pool, nil
 }
 
 func (p *Pool) Close() {
+	if p.stop != nil {
+		p.stop()
+		p.wg.Wait()
+	}
+	for _, r := range p.replicas {
+		r.pool.Close()
+	}
 	if p.Pool != nil {
 		p.Pool.Close()
 	}
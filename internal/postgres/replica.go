@@ -0,0 +1,204 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sync/atomic"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const (
+	defaultMaxReplicationLag  = 10 * time.Second
+	defaultReplicaCheckPeriod = 5 * time.Second
+)
+
+// Стратегии выбора реплики для чтения.
+const (
+	RoundRobin       = "round-robin"
+	LeastConnections = "least-connections"
+)
+
+const replicationLagQuery = `
+SELECT pg_is_in_recovery(),
+       CASE
+           WHEN NOT pg_is_in_recovery() THEN 0
+           WHEN pg_last_wal_receive_lsn() = pg_last_wal_replay_lsn() THEN 0
+           ELSE COALESCE(EXTRACT(EPOCH FROM now() - pg_last_xact_replay_timestamp()), 0)
+       END::float8`
+
+type ReplicaConfig struct {
+	DSNs        []string
+	Strategy    string
+	MaxLag      time.Duration
+	CheckPeriod time.Duration
+}
+
+type replica struct {
+	pool    *pgxpool.Pool
+	host    string
+	healthy atomic.Bool
+	lag     atomic.Int64
+}
+
+// WithReplicas добавляет реплики, на которые направляются читающие запросы.
+func WithReplicas(dsns ...string) Option {
+	return func(c *Config) error {
+		for _, dsn := range dsns {
+			if dsn == "" {
+				return errors.New("replica dsn cannot be empty")
+			}
+		}
+		c.Replicas.DSNs = append(c.Replicas.DSNs, dsns...)
+		return nil
+	}
+}
+
+// WithReplicaStrategy задаёт стратегию выбора реплики: round-robin или least-connections.
+func WithReplicaStrategy(strategy string) Option {
+	return func(c *Config) error {
+		if strategy != RoundRobin && strategy != LeastConnections {
+			return fmt.Errorf("unsupported replica strategy: %s", strategy)
+		}
+		c.Replicas.Strategy = strategy
+		return nil
+	}
+}
+
+// WithMaxReplicationLag задаёт отставание, после которого реплика исключается из балансировки.
+func WithMaxReplicationLag(lag time.Duration) Option {
+	return func(c *Config) error {
+		if lag <= 0 {
+			return errors.New("maxReplicationLag must be positive")
+		}
+		c.Replicas.MaxLag = lag
+		return nil
+	}
+}
+
+func WithReplicaCheckPeriod(period time.Duration) Option {
+	return func(c *Config) error {
+		if period <= 0 {
+			return errors.New("replicaCheckPeriod must be positive")
+		}
+		c.Replicas.CheckPeriod = period
+		return nil
+	}
+}
+
+type readOnlyKey struct{}
+
+// ReadOnly помечает контекст как читающий: TxManager.Reader будет использовать реплику.
+func ReadOnly(ctx context.Context) context.Context {
+	return context.WithValue(ctx, readOnlyKey{}, true)
+}
+
+func IsReadOnly(ctx context.Context) bool {
+	readOnly, _ := ctx.Value(readOnlyKey{}).(bool)
+	return readOnly
+}
+
+// Reader возвращает пул здоровой реплики или основной пул, если таких реплик нет.
+func (p *Pool) Reader() *pgxpool.Pool {
+	healthy := make([]*replica, 0, len(p.replicas))
+	for _, r := range p.replicas {
+		if r.healthy.Load() {
+			healthy = append(healthy, r)
+		}
+	}
+	if len(healthy) == 0 {
+		return p.Pool
+	}
+
+	i := p.pick(len(healthy), func(i int) int32 { return healthy[i].pool.Stat().AcquiredConns() })
+	return healthy[i].pool
+}
+
+// pick выбирает одну из n здоровых реплик по стратегии; acquired возвращает число занятых
+// соединений i-й реплики.
+func (p *Pool) pick(n int, acquired func(i int) int32) int {
+	if p.config.Replicas.Strategy == LeastConnections {
+		best, bestConns := 0, int32(math.MaxInt32)
+		for i := range n {
+			if conns := acquired(i); conns < bestConns {
+				best, bestConns = i, conns
+			}
+		}
+		return best
+	}
+
+	return int((p.next.Add(1) - 1) % uint64(n))
+}
+
+func (p *Pool) startReplicas(ctx context.Context) error {
+	if len(p.config.Replicas.DSNs) == 0 {
+		return nil
+	}
+
+	for _, dsn := range p.config.Replicas.DSNs {
+		pool, err := newPool(ctx, dsn, p.config)
+		if err != nil {
+			return fmt.Errorf("replica: %w", err)
+		}
+		p.replicas = append(p.replicas, &replica{pool: pool, host: pool.Config().ConnConfig.Host})
+	}
+
+	for _, r := range p.replicas {
+		p.checkReplica(ctx, r)
+	}
+
+	checkCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+	p.stop = cancel
+	p.wg.Add(1)
+	go func() {
+		defer p.wg.Done()
+		ticker := time.NewTicker(p.config.Replicas.CheckPeriod)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-checkCtx.Done():
+				return
+			case <-ticker.C:
+				for _, r := range p.replicas {
+					p.checkReplica(checkCtx, r)
+				}
+			}
+		}
+	}()
+
+	return nil
+}
+
+func (p *Pool) checkReplica(ctx context.Context, r *replica) {
+	ctx, cancel := context.WithTimeout(ctx, p.config.ConnectTimeout)
+	defer cancel()
+
+	var (
+		inRecovery bool
+		lagSeconds float64
+	)
+	err := r.pool.QueryRow(ctx, replicationLagQuery).Scan(&inRecovery, &lagSeconds)
+	lag := time.Duration(lagSeconds * float64(time.Second))
+	r.lag.Store(int64(lag))
+
+	healthy := err == nil && lag <= p.config.Replicas.MaxLag
+	if r.healthy.Swap(healthy) == healthy || p.config.Logger == nil {
+		return
+	}
+
+	fields := map[string]any{
+		"host": r.host,
+		"lag":  lag.String(),
+	}
+	if err != nil {
+		fields["error"] = err.Error()
+	}
+	if healthy {
+		p.config.Logger.With(fields).Info("Replica is healthy")
+	} else {
+		p.config.Logger.With(fields).Warn("Replica is unhealthy, routing reads to primary")
+	}
+}
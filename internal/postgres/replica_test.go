@@ -0,0 +1,89 @@
+package postgres
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// lazyPool создаёт пул без подключения: соединения открываются только при первом запросе.
+func lazyPool(t *testing.T, host string) *pgxpool.Pool {
+	t.Helper()
+	pool, err := pgxpool.New(context.Background(), "postgres://"+host+":5432/app")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(pool.Close)
+	return pool
+}
+
+func TestPoolPick(t *testing.T) {
+	tests := []struct {
+		name     string
+		strategy string
+		acquired []int32
+		want     []int
+	}{
+		{name: "round-robin", strategy: RoundRobin, acquired: []int32{0, 0, 0}, want: []int{0, 1, 2, 0, 1}},
+		{name: "round-robin ignores connections", strategy: RoundRobin, acquired: []int32{5, 0}, want: []int{0, 1, 0}},
+		{name: "least connections", strategy: LeastConnections, acquired: []int32{3, 1, 2}, want: []int{1, 1}},
+		{name: "least connections tie", strategy: LeastConnections, acquired: []int32{2, 1, 1}, want: []int{1}},
+		{name: "single replica", strategy: LeastConnections, acquired: []int32{7}, want: []int{0}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := &Pool{config: &Config{Replicas: ReplicaConfig{Strategy: tt.strategy}}}
+			var got []int
+			for range tt.want {
+				got = append(got, p.pick(len(tt.acquired), func(i int) int32 { return tt.acquired[i] }))
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("picks = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPoolReader(t *testing.T) {
+	primary := lazyPool(t, "primary")
+	replicas := []*pgxpool.Pool{lazyPool(t, "replica1"), lazyPool(t, "replica2"), lazyPool(t, "replica3")}
+
+	tests := []struct {
+		name    string
+		healthy []bool
+		want    []*pgxpool.Pool
+	}{
+		{name: "no replicas", want: []*pgxpool.Pool{primary, primary}},
+		{name: "no healthy replicas", healthy: []bool{false, false, false}, want: []*pgxpool.Pool{primary, primary}},
+		{
+			name:    "all healthy",
+			healthy: []bool{true, true, true},
+			want:    []*pgxpool.Pool{replicas[0], replicas[1], replicas[2], replicas[0]},
+		},
+		{
+			name:    "unhealthy replica is skipped",
+			healthy: []bool{true, false, true},
+			want:    []*pgxpool.Pool{replicas[0], replicas[2], replicas[0]},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := &Pool{Pool: primary, config: &Config{Replicas: ReplicaConfig{Strategy: RoundRobin}}}
+			for i, healthy := range tt.healthy {
+				r := &replica{pool: replicas[i]}
+				r.healthy.Store(healthy)
+				p.replicas = append(p.replicas, r)
+			}
+
+			for i, want := range tt.want {
+				if got := p.Reader(); got != want {
+					t.Errorf("Reader call %d = %s, want %s", i, got.Config().ConnConfig.Host, want.Config().ConnConfig.Host)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,168 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"math/rand/v2"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+
+	"github.com/desulaidovich/app/pkg/log"
+)
+
+const (
+	defaultTxMaxAttempts  = 3
+	defaultTxRetryBackoff = 20 * time.Millisecond
+	defaultTxRetryMaxWait = 1 * time.Second
+)
+
+const (
+	codeSerializationFailure = "40001"
+	codeDeadlockDetected     = "40P01"
+	classConnectionException = "08"
+)
+
+// RetryHook вызывается перед каждой повторной попыткой транзакции.
+// attempt — номер попытки, которая завершилась ошибкой err.
+type RetryHook func(ctx context.Context, attempt int, err error, delay time.Duration)
+
+type retryPolicy struct {
+	maxAttempts int
+	backoff     time.Duration
+	maxBackoff  time.Duration
+	logger      log.Logger
+	hook        RetryHook
+}
+
+// WithTxMaxAttempts задаёт максимальное число попыток выполнить транзакцию.
+// Значение 1 отключает повторы.
+func WithTxMaxAttempts(attempts int) TxManagerOption {
+	return func(m *TxManager) error {
+		if attempts <= 0 {
+			return errors.New("maxAttempts must be positive")
+		}
+		m.retry.maxAttempts = attempts
+		return nil
+	}
+}
+
+// WithTxBackoff задаёт начальную и максимальную задержку между попытками.
+// Задержка удваивается с каждой попыткой и дополняется случайным джиттером.
+func WithTxBackoff(initial, maximum time.Duration) TxManagerOption {
+	return func(m *TxManager) error {
+		if initial <= 0 {
+			return errors.New("initial backoff must be positive")
+		}
+		if maximum < initial {
+			return errors.New("max backoff cannot be less than initial backoff")
+		}
+		m.retry.backoff = initial
+		m.retry.maxBackoff = maximum
+		return nil
+	}
+}
+
+func WithTxLogger(logger log.Logger) TxManagerOption {
+	return func(m *TxManager) error {
+		if logger == nil {
+			return errors.New("logger cannot be nil")
+		}
+		m.retry.logger = logger
+		return nil
+	}
+}
+
+// WithTxRetryHook регистрирует обработчик повторов, например для экспорта метрик.
+func WithTxRetryHook(hook RetryHook) TxManagerOption {
+	return func(m *TxManager) error {
+		if hook == nil {
+			return errors.New("retry hook cannot be nil")
+		}
+		m.retry.hook = hook
+		return nil
+	}
+}
+
+// commitError — ошибка COMMIT. Если соединение оборвалось во время COMMIT, неизвестно,
+// зафиксирована ли транзакция, поэтому такие ошибки не повторяются.
+type commitError struct {
+	err error
+}
+
+func (e *commitError) Error() string {
+	return "failed to commit transaction: " + e.err.Error()
+}
+
+func (e *commitError) Unwrap() error {
+	return e.err
+}
+
+// IsRetryable сообщает, имеет ли смысл повторить транзакцию, завершившуюся ошибкой err:
+// конфликт сериализации, взаимоблокировка или потеря соединения. Потеря соединения
+// во время COMMIT не повторяется: транзакция могла уже зафиксироваться.
+func IsRetryable(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) &&
+		(pgErr.Code == codeSerializationFailure || pgErr.Code == codeDeadlockDetected) {
+		return true
+	}
+
+	var commitErr *commitError
+	if errors.As(err, &commitErr) {
+		return false
+	}
+
+	if pgErr != nil {
+		return strings.HasPrefix(pgErr.Code, classConnectionException)
+	}
+
+	var connErr *pgconn.ConnectError
+	if errors.As(err, &connErr) {
+		return true
+	}
+
+	return pgconn.SafeToRetry(err)
+}
+
+func (p retryPolicy) delay(attempt int) time.Duration {
+	d := p.backoff << (attempt - 1)
+	if d <= 0 || d > p.maxBackoff {
+		d = p.maxBackoff
+	}
+	return d/2 + rand.N(d/2+1)
+}
+
+func (p retryPolicy) do(ctx context.Context, fn func() error) error {
+	for attempt := 1; ; attempt++ {
+		err := fn()
+		if err == nil || attempt >= p.maxAttempts || !IsRetryable(err) {
+			return err
+		}
+
+		delay := p.delay(attempt)
+		if p.logger != nil {
+			p.logger.With(map[string]any{
+				"attempt": attempt,
+				"delay":   delay.String(),
+				"error":   err.Error(),
+			}).Warn("Retrying transaction")
+		}
+		if p.hook != nil {
+			p.hook(ctx, attempt, err, delay)
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return errors.Join(err, ctx.Err())
+		case <-timer.C:
+		}
+	}
+}
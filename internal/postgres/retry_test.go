@@ -0,0 +1,138 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// safeToRetryError — ошибка pgconn, отправка которой гарантированно не дошла до сервера.
+type safeToRetryError struct{}
+
+func (safeToRetryError) Error() string     { return "write failed before sending" }
+func (safeToRetryError) SafeToRetry() bool { return true }
+
+func TestIsRetryable(t *testing.T) {
+	connLost := &pgconn.PgError{Code: "08006"}
+
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{name: "nil", err: nil},
+		{name: "serialization failure", err: &pgconn.PgError{Code: codeSerializationFailure}, want: true},
+		{name: "deadlock", err: &pgconn.PgError{Code: codeDeadlockDetected}, want: true},
+		{name: "wrapped deadlock", err: fmt.Errorf("update jobs: %w", &pgconn.PgError{Code: codeDeadlockDetected}), want: true},
+		{name: "connection exception", err: connLost, want: true},
+		{name: "connect error", err: &pgconn.ConnectError{Config: &pgconn.Config{}}, want: true},
+		{name: "safe to retry", err: safeToRetryError{}, want: true},
+		{name: "unique violation", err: &pgconn.PgError{Code: codeUniqueViolation}},
+		{name: "lock timeout", err: &pgconn.PgError{Code: "55P03"}},
+		{name: "serialization failure on commit", err: &commitError{err: &pgconn.PgError{Code: codeSerializationFailure}}, want: true},
+		{name: "connection exception on commit", err: &commitError{err: connLost}},
+		{name: "safe to retry on commit", err: &commitError{err: safeToRetryError{}}},
+		{name: "plain error", err: errors.New("boom")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsRetryable(tt.err); got != tt.want {
+				t.Errorf("IsRetryable = %t, want %t", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRetryPolicyDelay(t *testing.T) {
+	p := retryPolicy{backoff: 20 * time.Millisecond, maxBackoff: time.Second}
+
+	tests := []struct {
+		attempt int
+		max     time.Duration
+	}{
+		{attempt: 1, max: 20 * time.Millisecond},
+		{attempt: 2, max: 40 * time.Millisecond},
+		{attempt: 4, max: 160 * time.Millisecond},
+		{attempt: 6, max: 640 * time.Millisecond},
+		{attempt: 7, max: time.Second},
+		{attempt: 70, max: time.Second},
+	}
+
+	for _, tt := range tests {
+		t.Run(fmt.Sprint(tt.attempt), func(t *testing.T) {
+			for range 100 {
+				if d := p.delay(tt.attempt); d < tt.max/2 || d > tt.max {
+					t.Fatalf("delay(%d) = %v, want between %v and %v", tt.attempt, d, tt.max/2, tt.max)
+				}
+			}
+		})
+	}
+}
+
+func TestRetryPolicyDo(t *testing.T) {
+	tests := []struct {
+		name      string
+		err       error
+		wantCalls int
+	}{
+		{name: "success", wantCalls: 1},
+		{name: "serialization failure", err: &pgconn.PgError{Code: codeSerializationFailure}, wantCalls: 3},
+		{name: "deadlock", err: &pgconn.PgError{Code: codeDeadlockDetected}, wantCalls: 3},
+		{name: "connection error", err: &pgconn.PgError{Code: "08006"}, wantCalls: 3},
+		{name: "connection error on commit", err: &commitError{err: &pgconn.PgError{Code: "08006"}}, wantCalls: 1},
+		{name: "not retryable", err: &pgconn.PgError{Code: codeUniqueViolation}, wantCalls: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var retries []int
+			p := retryPolicy{
+				maxAttempts: 3,
+				backoff:     time.Millisecond,
+				maxBackoff:  time.Millisecond,
+				hook: func(_ context.Context, attempt int, _ error, _ time.Duration) {
+					retries = append(retries, attempt)
+				},
+			}
+
+			calls := 0
+			err := p.do(context.Background(), func() error {
+				calls++
+				return tt.err
+			})
+			if !errors.Is(err, tt.err) {
+				t.Errorf("error = %v, want %v", err, tt.err)
+			}
+			if calls != tt.wantCalls {
+				t.Errorf("calls = %d, want %d", calls, tt.wantCalls)
+			}
+			if len(retries) != tt.wantCalls-1 {
+				t.Errorf("hook called for attempts %v, want %d retries", retries, tt.wantCalls-1)
+			}
+		})
+	}
+}
+
+func TestRetryPolicyDoCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	p := retryPolicy{maxAttempts: 5, backoff: time.Hour, maxBackoff: time.Hour}
+	deadlock := &pgconn.PgError{Code: codeDeadlockDetected}
+
+	calls := 0
+	err := p.do(ctx, func() error {
+		calls++
+		cancel()
+		return deadlock
+	})
+	if calls != 1 {
+		t.Errorf("calls = %d, want 1", calls)
+	}
+	if !errors.Is(err, deadlock) || !errors.Is(err, context.Canceled) {
+		t.Errorf("error = %v, want the deadlock joined with context.Canceled", err)
+	}
+}
@@ -0,0 +1,182 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"time"
+	"unicode/utf8"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/desulaidovich/app/pkg/log"
+)
+
+const maxLoggedArgLen = 64
+
+type traceKey struct{}
+
+type traceData struct {
+	start time.Time
+	sql   string
+	args  []any
+}
+
+// tracer логирует запросы дольше threshold на уровне WARN, а при logAll — все запросы на уровне DEBUG.
+type tracer struct {
+	logger    log.Logger
+	threshold time.Duration
+	logAll    bool
+	logArgs   bool
+}
+
+var (
+	_ pgx.QueryTracer    = (*tracer)(nil)
+	_ pgx.BatchTracer    = (*tracer)(nil)
+	_ pgx.CopyFromTracer = (*tracer)(nil)
+)
+
+func (t *tracer) TraceQueryStart(ctx context.Context, _ *pgx.Conn, data pgx.TraceQueryStartData) context.Context {
+	return context.WithValue(ctx, traceKey{}, &traceData{start: time.Now(), sql: data.SQL, args: data.Args})
+}
+
+func (t *tracer) TraceQueryEnd(ctx context.Context, _ *pgx.Conn, data pgx.TraceQueryEndData) {
+	td, ok := ctx.Value(traceKey{}).(*traceData)
+	if !ok {
+		return
+	}
+
+	t.log(ctx, "Query", time.Since(td.start), data.Err, map[string]any{
+		"sql":  td.sql,
+		"args": t.args(td.args),
+		"rows": data.CommandTag.RowsAffected(),
+	})
+}
+
+func (t *tracer) TraceBatchStart(ctx context.Context, _ *pgx.Conn, data pgx.TraceBatchStartData) context.Context {
+	return context.WithValue(ctx, traceKey{}, &traceData{
+		start: time.Now(),
+		sql:   fmt.Sprintf("batch of %d queries", data.Batch.Len()),
+	})
+}
+
+func (t *tracer) TraceBatchQuery(ctx context.Context, _ *pgx.Conn, data pgx.TraceBatchQueryData) {
+	if !t.logAll {
+		return
+	}
+
+	t.logger.With(t.fields(ctx, map[string]any{
+		"sql":  data.SQL,
+		"args": t.args(data.Args),
+		"rows": data.CommandTag.RowsAffected(),
+	}, data.Err)).Debug("Batch query")
+}
+
+func (t *tracer) TraceBatchEnd(ctx context.Context, _ *pgx.Conn, data pgx.TraceBatchEndData) {
+	td, ok := ctx.Value(traceKey{}).(*traceData)
+	if !ok {
+		return
+	}
+
+	t.log(ctx, "Batch", time.Since(td.start), data.Err, map[string]any{
+		"sql": td.sql,
+	})
+}
+
+func (t *tracer) TraceCopyFromStart(ctx context.Context, _ *pgx.Conn, data pgx.TraceCopyFromStartData) context.Context {
+	return context.WithValue(ctx, traceKey{}, &traceData{
+		start: time.Now(),
+		sql:   fmt.Sprintf("COPY %s (%d columns) FROM STDIN", data.TableName.Sanitize(), len(data.ColumnNames)),
+	})
+}
+
+func (t *tracer) TraceCopyFromEnd(ctx context.Context, _ *pgx.Conn, data pgx.TraceCopyFromEndData) {
+	td, ok := ctx.Value(traceKey{}).(*traceData)
+	if !ok {
+		return
+	}
+
+	t.log(ctx, "CopyFrom", time.Since(td.start), data.Err, map[string]any{
+		"sql":  td.sql,
+		"rows": data.CommandTag.RowsAffected(),
+	})
+}
+
+func (t *tracer) log(ctx context.Context, kind string, duration time.Duration, err error, fields map[string]any) {
+	slow := t.threshold > 0 && duration >= t.threshold
+	if !slow && !t.logAll {
+		return
+	}
+
+	fields["duration"] = duration.String()
+	logger := t.logger.With(t.fields(ctx, fields, err))
+	if slow {
+		logger.Warn("Slow " + kind)
+		return
+	}
+	logger.Debug(kind)
+}
+
+func (t *tracer) fields(ctx context.Context, fields map[string]any, err error) map[string]any {
+	if id := log.RequestID(ctx); id != "" {
+		fields["request_id"] = id
+	}
+	if err != nil {
+		fields["error"] = err.Error()
+	}
+	return fields
+}
+
+// args готовит параметры запроса для лога. Без WithQueryArgsLogging значения скрываются:
+// остаются только тип и длина.
+func (t *tracer) args(args []any) []any {
+	if t.logArgs {
+		return sanitizeArgs(args)
+	}
+	return redactArgs(args)
+}
+
+// redactArgs заменяет значения параметров их типом, а строки и бинарные данные — ещё и длиной.
+func redactArgs(args []any) []any {
+	out := make([]any, len(args))
+	for i, arg := range args {
+		switch v := arg.(type) {
+		case nil:
+			out[i] = nil
+		case string:
+			out[i] = fmt.Sprintf("<string, %d chars>", utf8.RuneCountInString(v))
+		case []byte:
+			out[i] = fmt.Sprintf("<%d bytes>", len(v))
+		default:
+			out[i] = fmt.Sprintf("<%T>", v)
+		}
+	}
+	return out
+}
+
+// sanitizeArgs укорачивает длинные строки и заменяет бинарные данные их размером,
+// чтобы параметры запроса не раздували логи.
+func sanitizeArgs(args []any) []any {
+	out := make([]any, len(args))
+	for i, arg := range args {
+		switch v := arg.(type) {
+		case string:
+			out[i] = truncate(v)
+		case []byte:
+			out[i] = fmt.Sprintf("<%d bytes>", len(v))
+		case nil, bool, int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, float64:
+			out[i] = v
+		case time.Time:
+			out[i] = v.Format(time.RFC3339Nano)
+		default:
+			out[i] = truncate(fmt.Sprintf("%v", v))
+		}
+	}
+	return out
+}
+
+func truncate(s string) string {
+	if utf8.RuneCountInString(s) <= maxLoggedArgLen {
+		return s
+	}
+	return string([]rune(s)[:maxLoggedArgLen]) + fmt.Sprintf("...(%d chars)", utf8.RuneCountInString(s))
+}
@@ -0,0 +1,87 @@
+package postgres
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRedactArgs(t *testing.T) {
+	args := []any{nil, "hunter2", "пароль", []byte("secret"), 42, true, time.Time{}, []int{1, 2}}
+	want := []any{nil, "<string, 7 chars>", "<string, 6 chars>", "<6 bytes>", "<int>", "<bool>", "<time.Time>", "<[]int>"}
+
+	got := redactArgs(args)
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("redactArgs = %q, want %q", got, want)
+	}
+	for _, arg := range got {
+		if s, ok := arg.(string); ok && (strings.Contains(s, "hunter2") || strings.Contains(s, "secret")) {
+			t.Errorf("redactArgs leaked a value: %q", s)
+		}
+	}
+}
+
+func TestSanitizeArgs(t *testing.T) {
+	long := strings.Repeat("a", maxLoggedArgLen+10)
+	ts := time.Date(2026, 10, 18, 12, 0, 0, 5, time.UTC)
+
+	tests := []struct {
+		name string
+		arg  any
+		want any
+	}{
+		{name: "nil", arg: nil, want: nil},
+		{name: "short string", arg: "alice", want: "alice"},
+		{name: "long string", arg: long, want: strings.Repeat("a", maxLoggedArgLen) + "...(74 chars)"},
+		{name: "bytes", arg: []byte{1, 2, 3}, want: "<3 bytes>"},
+		{name: "int", arg: int64(7), want: int64(7)},
+		{name: "bool", arg: false, want: false},
+		{name: "float", arg: 1.5, want: 1.5},
+		{name: "time", arg: ts, want: "2026-10-18T12:00:00.000000005Z"},
+		{name: "other", arg: []int{1, 2}, want: "[1 2]"},
+		{name: "long other", arg: make([]int, 40), want: "[" + strings.Repeat("0 ", 32)[:maxLoggedArgLen-1] + "...(81 chars)"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := sanitizeArgs([]any{tt.arg})
+			if !reflect.DeepEqual(got[0], tt.want) {
+				t.Errorf("sanitizeArgs = %#v, want %#v", got[0], tt.want)
+			}
+		})
+	}
+}
+
+func TestTruncate(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{name: "empty", in: "", want: ""},
+		{name: "at limit", in: strings.Repeat("x", maxLoggedArgLen), want: strings.Repeat("x", maxLoggedArgLen)},
+		{name: "over limit", in: strings.Repeat("x", maxLoggedArgLen+1), want: strings.Repeat("x", maxLoggedArgLen) + "...(65 chars)"},
+		{name: "counts runes", in: strings.Repeat("я", maxLoggedArgLen), want: strings.Repeat("я", maxLoggedArgLen)},
+		{name: "cuts runes", in: strings.Repeat("я", maxLoggedArgLen+2), want: strings.Repeat("я", maxLoggedArgLen) + "...(66 chars)"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := truncate(tt.in); got != tt.want {
+				t.Errorf("truncate = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTracerArgs(t *testing.T) {
+	args := []any{"hunter2", 1}
+
+	if got, want := (&tracer{}).args(args), []any{"<string, 7 chars>", "<int>"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("args without logArgs = %q, want %q", got, want)
+	}
+	if got, want := (&tracer{logArgs: true}).args(args), []any{"hunter2", 1}; !reflect.DeepEqual(got, want) {
+		t.Errorf("args with logArgs = %v, want %v", got, want)
+	}
+}
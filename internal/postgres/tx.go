@@ -0,0 +1,169 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// Querier — общий набор методов pgxpool.Pool и pgx.Tx, которым пользуются репозитории.
+type Querier interface {
+	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+	SendBatch(ctx context.Context, b *pgx.Batch) pgx.BatchResults
+	CopyFrom(ctx context.Context, tableName pgx.Identifier, columnNames []string, rowSrc pgx.CopyFromSource) (int64, error)
+	Begin(ctx context.Context) (pgx.Tx, error)
+}
+
+type IsoLevel = pgx.TxIsoLevel
+
+const (
+	Serializable    = pgx.Serializable
+	RepeatableRead  = pgx.RepeatableRead
+	ReadCommitted   = pgx.ReadCommitted
+	ReadUncommitted = pgx.ReadUncommitted
+)
+
+// TxOptions задаёт параметры транзакции. Нулевое значение — настройки сервера по умолчанию.
+// Для вложенных вызовов WithinTx параметры игнорируются: создаётся savepoint во внешней транзакции.
+type TxOptions struct {
+	Isolation  IsoLevel
+	ReadOnly   bool
+	Deferrable bool
+}
+
+func (o TxOptions) pgx() pgx.TxOptions {
+	opts := pgx.TxOptions{IsoLevel: o.Isolation}
+	if o.ReadOnly {
+		opts.AccessMode = pgx.ReadOnly
+	}
+	if o.Deferrable {
+		opts.DeferrableMode = pgx.Deferrable
+	}
+	return opts
+}
+
+type txKey struct{}
+
+// TxManager выполняет функции в транзакции и передаёт её через контекст.
+type TxManager struct {
+	pool  *Pool
+	retry retryPolicy
+}
+
+type TxManagerOption func(*TxManager) error
+
+func NewTxManager(pool *Pool, opts ...TxManagerOption) (*TxManager, error) {
+	if pool == nil {
+		return nil, errors.New("postgres pool cannot be nil")
+	}
+
+	m := &TxManager{
+		pool: pool,
+		retry: retryPolicy{
+			maxAttempts: defaultTxMaxAttempts,
+			backoff:     defaultTxRetryBackoff,
+			maxBackoff:  defaultTxRetryMaxWait,
+		},
+	}
+	for _, opt := range opts {
+		if err := opt(m); err != nil {
+			return nil, fmt.Errorf("tx manager option: %w", err)
+		}
+	}
+
+	return m, nil
+}
+
+// WithinTx выполняет fn в транзакции. Если в ctx уже есть транзакция, fn выполняется
+// внутри savepoint: ошибка откатывает только вложенную часть.
+// Транзакции с opts.ReadOnly выполняются на реплике. Контекст ReadOnly на WithinTx не влияет:
+// fn может писать, а запись должна попасть в основную базу.
+// Внешняя транзакция целиком повторяется при конфликтах сериализации, взаимоблокировках
+// и ошибках соединения, поэтому fn не должна иметь побочных эффектов вне базы данных.
+// Обрыв соединения во время COMMIT не повторяется и возвращается вызывающему: транзакция
+// могла зафиксироваться, и повторять работу безопасно, только если она идемпотентна.
+func (m *TxManager) WithinTx(ctx context.Context, opts TxOptions, fn func(ctx context.Context) error) error {
+	if outer, ok := TxFromContext(ctx); ok {
+		tx, err := outer.Begin(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to begin savepoint: %w", err)
+		}
+		return runTx(ctx, tx, fn)
+	}
+
+	return m.retry.do(ctx, func() error {
+		// Реплика выбирается на каждой попытке: после ошибки соединения повтор уходит на другую.
+		var beginner interface {
+			BeginTx(ctx context.Context, txOptions pgx.TxOptions) (pgx.Tx, error)
+		} = m.pool
+		if opts.ReadOnly {
+			beginner = m.pool.Reader()
+		}
+
+		tx, err := beginner.BeginTx(ctx, opts.pgx())
+		if err != nil {
+			return fmt.Errorf("failed to begin transaction: %w", err)
+		}
+		return runTx(ctx, tx, fn)
+	})
+}
+
+// Querier возвращает транзакцию из контекста, а при её отсутствии — основной пул.
+// Пишущие запросы всегда идут через Querier, даже под контекстом ReadOnly.
+func (m *TxManager) Querier(ctx context.Context) Querier {
+	if tx, ok := TxFromContext(ctx); ok {
+		return tx
+	}
+	return m.pool
+}
+
+// Reader — аналог Querier для читающих запросов: для контекста, помеченного ReadOnly,
+// возвращается пул реплики.
+func (m *TxManager) Reader(ctx context.Context) Querier {
+	if tx, ok := TxFromContext(ctx); ok {
+		return tx
+	}
+	if IsReadOnly(ctx) {
+		return m.pool.Reader()
+	}
+	return m.pool
+}
+
+// ContextWithTx сохраняет tx в контексте так же, как WithinTx: Querier, Reader и вложенные
+// WithinTx будут работать в ней. Фиксация и откат остаются за владельцем tx.
+func ContextWithTx(ctx context.Context, tx pgx.Tx) context.Context {
+	return context.WithValue(ctx, txKey{}, tx)
+}
+
+// TxFromContext возвращает транзакцию, сохранённую в контексте WithinTx.
+func TxFromContext(ctx context.Context) (pgx.Tx, bool) {
+	tx, ok := ctx.Value(txKey{}).(pgx.Tx)
+	return tx, ok
+}
+
+func runTx(ctx context.Context, tx pgx.Tx, fn func(ctx context.Context) error) error {
+	defer func() {
+		if p := recover(); p != nil {
+			_ = tx.Rollback(context.WithoutCancel(ctx))
+			panic(p)
+		}
+	}()
+
+	if err := fn(ContextWithTx(ctx, tx)); err != nil {
+		if rbErr := tx.Rollback(context.WithoutCancel(ctx)); rbErr != nil && !errors.Is(rbErr, pgx.ErrTxClosed) {
+			return errors.Join(err, fmt.Errorf("failed to rollback transaction: %w", rbErr))
+		}
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return &commitError{err: err}
+	}
+
+	return nil
+}
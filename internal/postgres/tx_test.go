@@ -0,0 +1,297 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// fakeTx записывает вызовы Begin, Commit и Rollback в общий журнал.
+type fakeTx struct {
+	pgx.Tx
+	name      string
+	log       *[]string
+	commitErr error
+}
+
+func (tx *fakeTx) Begin(context.Context) (pgx.Tx, error) {
+	*tx.log = append(*tx.log, tx.name+": savepoint")
+	return &fakeTx{name: tx.name + "/savepoint", log: tx.log}, nil
+}
+
+func (tx *fakeTx) Commit(context.Context) error {
+	*tx.log = append(*tx.log, tx.name+": commit")
+	return tx.commitErr
+}
+
+func (tx *fakeTx) Rollback(context.Context) error {
+	*tx.log = append(*tx.log, tx.name+": rollback")
+	return nil
+}
+
+func TestRunTx(t *testing.T) {
+	errFn := errors.New("insert failed")
+	errCommit := &pgconn.PgError{Code: "08006"}
+
+	tests := []struct {
+		name      string
+		fn        func(ctx context.Context) error
+		commitErr error
+		wantErr   error
+		wantPanic bool
+		wantLog   []string
+	}{
+		{
+			name:    "commit",
+			fn:      func(context.Context) error { return nil },
+			wantLog: []string{"tx: commit"},
+		},
+		{
+			name:    "rollback on error",
+			fn:      func(context.Context) error { return errFn },
+			wantErr: errFn,
+			wantLog: []string{"tx: rollback"},
+		},
+		{
+			name:      "rollback on panic",
+			fn:        func(context.Context) error { panic("boom") },
+			wantPanic: true,
+			wantLog:   []string{"tx: rollback"},
+		},
+		{
+			name:      "commit error",
+			fn:        func(context.Context) error { return nil },
+			commitErr: errCommit,
+			wantErr:   errCommit,
+			wantLog:   []string{"tx: commit"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var log []string
+			tx := &fakeTx{name: "tx", log: &log, commitErr: tt.commitErr}
+
+			var err error
+			panicked := func() (panicked bool) {
+				defer func() { panicked = recover() != nil }()
+				err = runTx(context.Background(), tx, tt.fn)
+				return false
+			}()
+
+			if panicked != tt.wantPanic {
+				t.Errorf("panicked = %t, want %t", panicked, tt.wantPanic)
+			}
+			if !errors.Is(err, tt.wantErr) || (tt.wantErr == nil && err != nil) {
+				t.Errorf("error = %v, want %v", err, tt.wantErr)
+			}
+			if !slices.Equal(log, tt.wantLog) {
+				t.Errorf("log = %q, want %q", log, tt.wantLog)
+			}
+		})
+	}
+
+	t.Run("commit error is not retried", func(t *testing.T) {
+		var log []string
+		err := runTx(context.Background(), &fakeTx{name: "tx", log: &log, commitErr: errCommit},
+			func(context.Context) error { return nil })
+		if IsRetryable(err) {
+			t.Errorf("IsRetryable(%v) = true for a connection error from COMMIT", err)
+		}
+	})
+}
+
+func TestWithinTxNested(t *testing.T) {
+	errInner := errors.New("inner failed")
+	m := &TxManager{pool: &Pool{}}
+
+	var log []string
+	outer := &fakeTx{name: "tx", log: &log}
+	ctx := ContextWithTx(context.Background(), outer)
+
+	var inner Querier
+	err := m.WithinTx(ctx, TxOptions{Isolation: Serializable}, func(ctx context.Context) error {
+		inner = m.Querier(ctx)
+		return errInner
+	})
+	if !errors.Is(err, errInner) {
+		t.Errorf("error = %v, want %v", err, errInner)
+	}
+	if want := []string{"tx: savepoint", "tx/savepoint: rollback"}; !slices.Equal(log, want) {
+		t.Errorf("log = %q, want %q", log, want)
+	}
+	if tx, ok := inner.(*fakeTx); !ok || tx.name != "tx/savepoint" {
+		t.Errorf("Querier inside nested WithinTx = %v, want the savepoint", inner)
+	}
+}
+
+func TestTxManagerQuerier(t *testing.T) {
+	m := &TxManager{pool: &Pool{}}
+	tx := &fakeTx{name: "tx"}
+	inTx := ContextWithTx(context.Background(), tx)
+
+	tests := []struct {
+		name string
+		got  Querier
+		want Querier
+	}{
+		{name: "querier without transaction", got: m.Querier(context.Background()), want: m.pool},
+		{name: "querier in transaction", got: m.Querier(inTx), want: tx},
+		{name: "querier in read-only transaction", got: m.Querier(ReadOnly(inTx)), want: tx},
+		{name: "reader without transaction", got: m.Reader(context.Background()), want: m.pool},
+		{name: "reader in transaction", got: m.Reader(inTx), want: tx},
+		{name: "reader in read-only transaction", got: m.Reader(ReadOnly(inTx)), want: tx},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.got != tt.want {
+				t.Errorf("got %T %p, want %T %p", tt.got, tt.got, tt.want, tt.want)
+			}
+		})
+	}
+}
+
+// testPool подключается к базе из TEST_DATABASE_DSN и создаёт пустую таблицу items.
+// Без TEST_DATABASE_DSN тест пропускается.
+func testPool(t *testing.T) (*Pool, string) {
+	t.Helper()
+
+	dsn := os.Getenv("TEST_DATABASE_DSN")
+	if dsn == "" {
+		t.Skip("TEST_DATABASE_DSN is not set")
+	}
+
+	ctx := context.Background()
+	pool, err := New(ctx, WithDSN(dsn), WithMinConns(1))
+	if err != nil {
+		t.Fatalf("failed to connect: %v", err)
+	}
+	t.Cleanup(pool.Close)
+
+	table := fmt.Sprintf("tx_test_%d", time.Now().UnixNano())
+	if _, err := pool.Exec(ctx, "CREATE TABLE "+table+" (name TEXT NOT NULL)"); err != nil {
+		t.Fatalf("failed to create table: %v", err)
+	}
+	t.Cleanup(func() {
+		_, _ = pool.Exec(context.Background(), "DROP TABLE "+table)
+	})
+	return pool, table
+}
+
+func TestWithinTxDatabase(t *testing.T) {
+	pool, table := testPool(t)
+	m, err := NewTxManager(pool, WithTxBackoff(time.Millisecond, time.Millisecond))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	insert := func(ctx context.Context, name string) error {
+		_, err := m.Querier(ctx).Exec(ctx, "INSERT INTO "+table+" (name) VALUES ($1)", name)
+		return err
+	}
+	errFn := errors.New("fail")
+
+	tests := []struct {
+		name    string
+		fn      func(ctx context.Context) error
+		want    []string
+		wantErr bool
+	}{
+		{
+			name: "commit",
+			fn:   func(ctx context.Context) error { return insert(ctx, "a") },
+			want: []string{"a"},
+		},
+		{
+			name: "rollback on error",
+			fn: func(ctx context.Context) error {
+				if err := insert(ctx, "a"); err != nil {
+					return err
+				}
+				return errFn
+			},
+			wantErr: true,
+		},
+		{
+			name: "rollback on panic",
+			fn: func(ctx context.Context) error {
+				if err := insert(ctx, "a"); err != nil {
+					return err
+				}
+				panic("boom")
+			},
+			wantErr: true,
+		},
+		{
+			name: "failed savepoint keeps the outer transaction",
+			fn: func(ctx context.Context) error {
+				if err := insert(ctx, "outer"); err != nil {
+					return err
+				}
+				err := m.WithinTx(ctx, TxOptions{}, func(ctx context.Context) error {
+					if err := insert(ctx, "inner"); err != nil {
+						return err
+					}
+					return errFn
+				})
+				if !errors.Is(err, errFn) {
+					return fmt.Errorf("nested WithinTx: %v", err)
+				}
+				return m.WithinTx(ctx, TxOptions{}, func(ctx context.Context) error { return insert(ctx, "second") })
+			},
+			want: []string{"outer", "second"},
+		},
+		{
+			name: "outer error rolls back committed savepoints",
+			fn: func(ctx context.Context) error {
+				if err := m.WithinTx(ctx, TxOptions{}, func(ctx context.Context) error { return insert(ctx, "inner") }); err != nil {
+					return err
+				}
+				return errFn
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			if _, err := pool.Exec(ctx, "TRUNCATE "+table); err != nil {
+				t.Fatal(err)
+			}
+
+			err := func() (err error) {
+				defer func() {
+					if p := recover(); p != nil {
+						err = fmt.Errorf("panic: %v", p)
+					}
+				}()
+				return m.WithinTx(ctx, TxOptions{}, tt.fn)
+			}()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error = %v, want error %t", err, tt.wantErr)
+			}
+
+			rows, err := pool.Query(ctx, "SELECT name FROM "+table+" ORDER BY name")
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := pgx.CollectRows(rows, pgx.RowTo[string])
+			if err != nil {
+				t.Fatal(err)
+			}
+			slices.Sort(tt.want)
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("rows = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,164 @@
+package queue
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/desulaidovich/app/internal/postgres"
+)
+
+const (
+	StatusPending   = "pending"
+	StatusRunning   = "running"
+	StatusCompleted = "completed"
+	StatusDead      = "dead"
+	StatusCancelled = "cancelled"
+)
+
+const defaultListLimit = 50
+
+var ErrInvalidState = errors.New("job is not in a state that allows this operation")
+
+const jobColumns = `id, queue, kind, payload, status, attempts, max_attempts,
+	COALESCE(unique_key, ''), COALESCE(last_error, ''), run_at, created_at, updated_at, finished_at`
+
+type Job struct {
+	ID          int64
+	Queue       string
+	Kind        string
+	Payload     json.RawMessage
+	Status      string
+	Attempts    int32
+	MaxAttempts int32
+	UniqueKey   string
+	LastError   string
+	RunAt       time.Time
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+	FinishedAt  *time.Time
+}
+
+type ListFilter struct {
+	Queue   string
+	Kind    string
+	Status  string
+	Limit   int
+	AfterID int64
+}
+
+func scanJob(row pgx.Row) (*Job, error) {
+	var j Job
+	err := row.Scan(&j.ID, &j.Queue, &j.Kind, &j.Payload, &j.Status, &j.Attempts, &j.MaxAttempts,
+		&j.UniqueKey, &j.LastError, &j.RunAt, &j.CreatedAt, &j.UpdatedAt, &j.FinishedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &j, nil
+}
+
+// List возвращает задачи по возрастанию id, начиная после filter.AfterID.
+func (q *Queue) List(ctx context.Context, filter ListFilter) ([]*Job, error) {
+	var (
+		conds = []string{"id > $1"}
+		args  = []any{filter.AfterID}
+	)
+	for _, f := range [][2]string{{"queue", filter.Queue}, {"kind", filter.Kind}, {"status", filter.Status}} {
+		if f[1] != "" {
+			args = append(args, f[1])
+			conds = append(conds, fmt.Sprintf("%s = $%d", f[0], len(args)))
+		}
+	}
+
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = defaultListLimit
+	}
+	args = append(args, limit)
+
+	rows, err := q.tx.Reader(ctx).Query(ctx, fmt.Sprintf(
+		"SELECT %s FROM jobs WHERE %s ORDER BY id LIMIT $%d",
+		jobColumns, strings.Join(conds, " AND "), len(args),
+	), args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list jobs: %w", err)
+	}
+	defer rows.Close()
+
+	var jobs []*Job
+	for rows.Next() {
+		job, err := scanJob(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan job: %w", err)
+		}
+		jobs = append(jobs, job)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list jobs: %w", err)
+	}
+
+	return jobs, nil
+}
+
+// Retry возвращает упавшую или отменённую задачу в очередь со сброшенным счётчиком попыток.
+func (q *Queue) Retry(ctx context.Context, id int64) (*Job, error) {
+	return q.transition(ctx, id, []string{StatusDead, StatusCancelled}, `
+		UPDATE jobs
+		SET status = 'pending', attempts = 0, run_at = now(), finished_at = NULL, updated_at = now()
+		WHERE id = $1
+		RETURNING `+jobColumns)
+}
+
+// Cancel отменяет ещё не запущенную задачу.
+func (q *Queue) Cancel(ctx context.Context, id int64) (*Job, error) {
+	return q.transition(ctx, id, []string{StatusPending}, `
+		UPDATE jobs
+		SET status = 'cancelled', finished_at = now(), updated_at = now()
+		WHERE id = $1
+		RETURNING `+jobColumns)
+}
+
+func (q *Queue) transition(ctx context.Context, id int64, from []string, update string) (*Job, error) {
+	var job *Job
+	err := q.tx.WithinTx(ctx, postgres.TxOptions{}, func(ctx context.Context) error {
+		db := q.tx.Querier(ctx)
+
+		var status string
+		if err := db.QueryRow(ctx, "SELECT status FROM jobs WHERE id = $1 FOR UPDATE", id).Scan(&status); err != nil {
+			return postgres.Classify(err)
+		}
+		if !slices.Contains(from, status) {
+			return fmt.Errorf("%w: job %d is %s", ErrInvalidState, id, status)
+		}
+
+		var err error
+		job, err = scanJob(db.QueryRow(ctx, update, id))
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if job.Status == StatusPending {
+		q.notify(ctx, job.Queue)
+	}
+	return job, nil
+}
+
+// Purge удаляет завершённые и отменённые задачи, закончившиеся раньше olderThan назад.
+// Упавшие задачи остаются для разбора. Возвращает число удалённых задач.
+func (q *Queue) Purge(ctx context.Context, olderThan time.Duration) (int64, error) {
+	tag, err := q.tx.Querier(ctx).Exec(ctx, `
+		DELETE FROM jobs
+		WHERE status IN ('completed', 'cancelled') AND finished_at < now() - $1::interval`, olderThan)
+	if err != nil {
+		return 0, fmt.Errorf("failed to purge jobs: %w", err)
+	}
+	return tag.RowsAffected(), nil
+}
@@ -0,0 +1,327 @@
+package queue
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+
+	"github.com/desulaidovich/app/internal/postgres"
+	"github.com/desulaidovich/app/pkg/log"
+)
+
+const (
+	DefaultQueue = "default"
+
+	defaultConcurrency  = 10
+	defaultMaxAttempts  = 25
+	defaultPollInterval = 1 * time.Second
+	defaultJobTimeout   = 5 * time.Minute
+	defaultRetryBackoff = 1 * time.Second
+	defaultMaxBackoff   = 1 * time.Hour
+	defaultRescuePeriod = 1 * time.Minute
+
+	notifyChannel = "jobs"
+)
+
+var ErrDuplicate = errors.New("job with the same unique key is already pending")
+
+type handlerFunc func(ctx context.Context, job *Job) error
+
+// Queue — очередь фоновых задач поверх таблицы jobs. Задачи разбираются воркерами
+// через SELECT ... FOR UPDATE SKIP LOCKED, поэтому несколько экземпляров приложения
+// могут обрабатывать одну очередь. Реализует runner.Handler.
+type Queue struct {
+	db           *postgres.Pool
+	tx           *postgres.TxManager
+	logger       log.Logger
+	listener     *postgres.Listener
+	queues       map[string]int
+	handlers     map[string]handlerFunc
+	pollInterval time.Duration
+	jobTimeout   time.Duration
+	backoff      time.Duration
+	maxBackoff   time.Duration
+	// instance отличает воркеров этого экземпляра в jobs.locked_by.
+	instance string
+
+	mu      sync.Mutex
+	wake    map[string]chan struct{}
+	started bool
+	cancel  context.CancelFunc
+	abort   context.CancelFunc
+	wg      sync.WaitGroup
+}
+
+type Option func(*Queue) error
+
+func WithLogger(logger log.Logger) Option {
+	return func(q *Queue) error {
+		if logger == nil {
+			return errors.New("logger cannot be nil")
+		}
+		q.logger = logger
+		return nil
+	}
+}
+
+// WithListener позволяет будить воркеров по NOTIFY сразу после постановки задачи,
+// не дожидаясь очередного опроса. Слушатель должен быть запущен отдельно.
+func WithListener(listener *postgres.Listener) Option {
+	return func(q *Queue) error {
+		if listener == nil {
+			return errors.New("listener cannot be nil")
+		}
+		q.listener = listener
+		return nil
+	}
+}
+
+// WithQueue задаёт очередь, которую обрабатывает этот экземпляр, и число воркеров для неё.
+// Если ни одна очередь не задана, обрабатывается DefaultQueue.
+func WithQueue(name string, concurrency int) Option {
+	return func(q *Queue) error {
+		if name == "" {
+			return errors.New("queue name cannot be empty")
+		}
+		if concurrency <= 0 {
+			return errors.New("concurrency must be positive")
+		}
+		q.queues[name] = concurrency
+		return nil
+	}
+}
+
+func WithPollInterval(interval time.Duration) Option {
+	return func(q *Queue) error {
+		if interval <= 0 {
+			return errors.New("pollInterval must be positive")
+		}
+		q.pollInterval = interval
+		return nil
+	}
+}
+
+// WithJobTimeout ограничивает время выполнения одной задачи.
+func WithJobTimeout(timeout time.Duration) Option {
+	return func(q *Queue) error {
+		if timeout <= 0 {
+			return errors.New("jobTimeout must be positive")
+		}
+		q.jobTimeout = timeout
+		return nil
+	}
+}
+
+// WithRetryBackoff задаёт начальную и максимальную задержку перед повтором упавшей задачи.
+func WithRetryBackoff(initial, maximum time.Duration) Option {
+	return func(q *Queue) error {
+		if initial <= 0 {
+			return errors.New("initial backoff must be positive")
+		}
+		if maximum < initial {
+			return errors.New("max backoff cannot be less than initial backoff")
+		}
+		q.backoff = initial
+		q.maxBackoff = maximum
+		return nil
+	}
+}
+
+func New(db *postgres.Pool, tx *postgres.TxManager, opts ...Option) (*Queue, error) {
+	if db == nil {
+		return nil, errors.New("postgres pool cannot be nil")
+	}
+	if tx == nil {
+		return nil, errors.New("tx manager cannot be nil")
+	}
+
+	q := &Queue{
+		db:           db,
+		tx:           tx,
+		queues:       make(map[string]int),
+		handlers:     make(map[string]handlerFunc),
+		pollInterval: defaultPollInterval,
+		jobTimeout:   defaultJobTimeout,
+		backoff:      defaultRetryBackoff,
+		maxBackoff:   defaultMaxBackoff,
+		wake:         make(map[string]chan struct{}),
+		instance:     newInstanceID(),
+	}
+	for _, opt := range opts {
+		if err := opt(q); err != nil {
+			return nil, fmt.Errorf("queue option: %w", err)
+		}
+	}
+	if q.logger == nil {
+		return nil, errors.New("logger is required")
+	}
+	if len(q.queues) == 0 {
+		q.queues[DefaultQueue] = defaultConcurrency
+	}
+
+	for name, concurrency := range q.queues {
+		q.wake[name] = make(chan struct{}, concurrency)
+	}
+	if q.listener != nil {
+		if err := q.listener.Listen(notifyChannel, func(_ context.Context, n *pgconn.Notification) {
+			q.signal(n.Payload)
+		}); err != nil {
+			return nil, fmt.Errorf("failed to subscribe to job notifications: %w", err)
+		}
+	}
+
+	return q, nil
+}
+
+func newInstanceID() string {
+	host, err := os.Hostname()
+	if err != nil {
+		host = "unknown"
+	}
+	return fmt.Sprintf("%s:%d:%04x", host, os.Getpid(), rand.N(0x10000))
+}
+
+// Register регистрирует обработчик задач вида kind. Аргументы задачи хранятся в JSON
+// и декодируются в T перед вызовом fn. Регистрация выполняется до Start.
+func Register[T any](q *Queue, kind string, fn func(ctx context.Context, job *Job, args T) error) error {
+	if kind == "" {
+		return errors.New("job kind cannot be empty")
+	}
+	if fn == nil {
+		return errors.New("job handler cannot be nil")
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.started {
+		return errors.New("queue already started")
+	}
+	if _, exists := q.handlers[kind]; exists {
+		return fmt.Errorf("handler for job kind %s already registered", kind)
+	}
+
+	q.handlers[kind] = func(ctx context.Context, job *Job) error {
+		var args T
+		if err := json.Unmarshal(job.Payload, &args); err != nil {
+			return fmt.Errorf("failed to decode job arguments: %w", err)
+		}
+		return fn(ctx, job, args)
+	}
+	return nil
+}
+
+type enqueueParams struct {
+	queue       string
+	runAt       time.Time
+	maxAttempts int
+	uniqueKey   string
+}
+
+type EnqueueOption func(*enqueueParams)
+
+// InQueue ставит задачу в указанную очередь вместо DefaultQueue.
+func InQueue(name string) EnqueueOption {
+	return func(p *enqueueParams) { p.queue = name }
+}
+
+// At откладывает выполнение задачи до момента t.
+func At(t time.Time) EnqueueOption {
+	return func(p *enqueueParams) { p.runAt = t }
+}
+
+// After откладывает выполнение задачи на d.
+func After(d time.Duration) EnqueueOption {
+	return func(p *enqueueParams) { p.runAt = time.Now().Add(d) }
+}
+
+func MaxAttempts(n int) EnqueueOption {
+	return func(p *enqueueParams) { p.maxAttempts = n }
+}
+
+// Unique запрещает ставить задачу того же вида с тем же ключом, пока предыдущая
+// не завершена. Повторная постановка возвращает ErrDuplicate.
+func Unique(key string) EnqueueOption {
+	return func(p *enqueueParams) { p.uniqueKey = key }
+}
+
+// Enqueue ставит задачу в очередь. Если в ctx есть транзакция TxManager,
+// задача станет видна воркерам только после её фиксации.
+func (q *Queue) Enqueue(ctx context.Context, kind string, args any, opts ...EnqueueOption) (*Job, error) {
+	if kind == "" {
+		return nil, errors.New("job kind cannot be empty")
+	}
+
+	params := enqueueParams{queue: DefaultQueue, maxAttempts: defaultMaxAttempts}
+	for _, opt := range opts {
+		opt(&params)
+	}
+	if params.maxAttempts <= 0 {
+		return nil, errors.New("max attempts must be positive")
+	}
+
+	payload, err := json.Marshal(args)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode job arguments: %w", err)
+	}
+
+	var (
+		runAt     *time.Time
+		uniqueKey *string
+	)
+	if !params.runAt.IsZero() {
+		runAt = &params.runAt
+	}
+	if params.uniqueKey != "" {
+		uniqueKey = &params.uniqueKey
+	}
+
+	db := q.tx.Querier(ctx)
+	job, err := scanJob(db.QueryRow(ctx, `
+		INSERT INTO jobs (queue, kind, payload, max_attempts, unique_key, run_at)
+		VALUES ($1, $2, $3, $4, $5, COALESCE($6, now()))
+		ON CONFLICT (kind, unique_key) WHERE unique_key IS NOT NULL AND status IN ('pending', 'running')
+		DO NOTHING
+		RETURNING `+jobColumns,
+		params.queue, kind, payload, params.maxAttempts, uniqueKey, runAt,
+	))
+	if err != nil {
+		if errors.Is(postgres.Classify(err), postgres.ErrNotFound) && uniqueKey != nil {
+			return nil, fmt.Errorf("%w: %s/%s", ErrDuplicate, kind, params.uniqueKey)
+		}
+		return nil, fmt.Errorf("failed to enqueue job: %w", err)
+	}
+
+	if err := postgres.Notify(ctx, db, notifyChannel, params.queue); err != nil {
+		return nil, err
+	}
+
+	return job, nil
+}
+
+func (q *Queue) notify(ctx context.Context, queue string) {
+	if err := postgres.Notify(ctx, q.tx.Querier(ctx), notifyChannel, queue); err != nil {
+		q.logger.With(map[string]any{
+			"queue": queue,
+			"error": err.Error(),
+		}).Warn("Failed to notify workers")
+	}
+}
+
+func (q *Queue) signal(queue string) {
+	ch, ok := q.wake[queue]
+	if !ok {
+		return
+	}
+	select {
+	case ch <- struct{}{}:
+	default:
+	}
+}
@@ -0,0 +1,255 @@
+package queue
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"math/rand/v2"
+	"slices"
+	"time"
+
+	"github.com/desulaidovich/app/internal/postgres"
+	"github.com/desulaidovich/app/pkg/log"
+)
+
+// Start запускает воркеров всех очередей и блокируется до отмены ctx.
+// Если не зарегистрировано ни одного обработчика, воркеры не запускаются.
+func (q *Queue) Start(ctx context.Context) error {
+	q.mu.Lock()
+	if q.started {
+		q.mu.Unlock()
+		return errors.New("queue already started")
+	}
+	q.started = true
+	runCtx, cancel := context.WithCancel(ctx)
+	jobCtx, abort := context.WithCancel(context.WithoutCancel(ctx))
+	q.cancel, q.abort = cancel, abort
+	q.mu.Unlock()
+
+	if len(q.handlers) == 0 {
+		<-runCtx.Done()
+		return nil
+	}
+
+	kinds := slices.Sorted(maps.Keys(q.handlers))
+	for name, concurrency := range q.queues {
+		for i := range concurrency {
+			worker := fmt.Sprintf("%s/%s/%d", q.instance, name, i)
+			q.wg.Go(func() { q.work(runCtx, jobCtx, worker, name, kinds) })
+		}
+	}
+	q.wg.Go(func() { q.rescue(runCtx) })
+
+	q.logger.With(map[string]any{
+		"queues": q.queues,
+		"kinds":  kinds,
+	}).Info("Job queue started")
+
+	<-runCtx.Done()
+	return nil
+}
+
+// Stop прекращает выборку новых задач и ждёт завершения выполняющихся.
+// Если ctx истекает раньше, контексты задач отменяются, и они будут повторены позже.
+func (q *Queue) Stop(ctx context.Context) error {
+	q.mu.Lock()
+	cancel, abort := q.cancel, q.abort
+	q.mu.Unlock()
+
+	if cancel == nil {
+		return nil
+	}
+	cancel()
+
+	done := make(chan struct{})
+	go func() {
+		q.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		abort()
+		return nil
+	case <-ctx.Done():
+		abort()
+		<-done
+		return fmt.Errorf("queue stop: %w", ctx.Err())
+	}
+}
+
+func (q *Queue) work(ctx, jobCtx context.Context, worker, queue string, kinds []string) {
+	ticker := time.NewTicker(q.pollInterval)
+	defer ticker.Stop()
+
+	for {
+		job, err := q.fetch(ctx, worker, queue, kinds)
+		if err != nil && ctx.Err() == nil {
+			q.logger.With(map[string]any{
+				"queue": queue,
+				"error": err.Error(),
+			}).Error("Failed to fetch job")
+		}
+		if job != nil {
+			q.process(jobCtx, worker, job)
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-q.wake[queue]:
+		case <-ticker.C:
+		}
+	}
+}
+
+func (q *Queue) fetch(ctx context.Context, worker, queue string, kinds []string) (*Job, error) {
+	job, err := scanJob(q.db.QueryRow(ctx, `
+		UPDATE jobs
+		SET status = 'running', attempts = attempts + 1, locked_at = now(), locked_by = $3, updated_at = now()
+		WHERE id = (
+			SELECT id FROM jobs
+			WHERE queue = $1 AND status = 'pending' AND run_at <= now() AND kind = ANY($2)
+			ORDER BY run_at, id
+			LIMIT 1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING `+jobColumns, queue, kinds, worker))
+	if errors.Is(postgres.Classify(err), postgres.ErrNotFound) {
+		return nil, nil
+	}
+	return job, err
+}
+
+func (q *Queue) process(ctx context.Context, worker string, job *Job) {
+	ctx, cancel := context.WithTimeout(ctx, q.jobTimeout)
+	defer cancel()
+
+	start := time.Now()
+	err := q.run(ctx, job)
+	duration := time.Since(start)
+
+	// Результат записывается даже при отменённом контексте задачи.
+	saveCtx := context.WithoutCancel(ctx)
+	if err == nil {
+		if saveErr := q.finish(saveCtx, worker, job, `
+			SET status = 'completed', locked_at = NULL, locked_by = NULL, last_error = NULL,
+			    finished_at = now(), updated_at = now()`); saveErr != nil {
+			q.logSaveError(job, duration, saveErr, "Failed to mark job completed")
+			return
+		}
+		q.logJob(job, duration, nil).Debug("Job completed")
+		return
+	}
+
+	if job.Attempts >= job.MaxAttempts {
+		if saveErr := q.finish(saveCtx, worker, job, `
+			SET status = 'dead', locked_at = NULL, locked_by = NULL, last_error = $3,
+			    finished_at = now(), updated_at = now()`, err.Error()); saveErr != nil {
+			q.logSaveError(job, duration, saveErr, "Failed to mark job dead")
+			return
+		}
+		q.logJob(job, duration, err).Error("Job moved to dead state")
+		return
+	}
+
+	delay := q.retryDelay(int(job.Attempts))
+	if saveErr := q.finish(saveCtx, worker, job, `
+		SET status = 'pending', locked_at = NULL, locked_by = NULL, last_error = $3,
+		    run_at = now() + $4::interval, updated_at = now()`, err.Error(), delay); saveErr != nil {
+		q.logSaveError(job, duration, saveErr, "Failed to reschedule job")
+		return
+	}
+	q.logJob(job, duration, err).With(map[string]any{
+		"retry_in": delay.String(),
+	}).Warn("Job failed, retry scheduled")
+}
+
+// errLeaseLost — задача больше не принадлежит воркеру: rescue вернул её в очередь,
+// и её мог взять другой воркер. Результат такого выполнения отбрасывается.
+var errLeaseLost = errors.New("job lease lost")
+
+// finish записывает результат задачи, если она всё ещё выполняется этим воркером.
+// set — SET-часть UPDATE, $1 и $2 заняты id задачи и воркером.
+func (q *Queue) finish(ctx context.Context, worker string, job *Job, set string, args ...any) error {
+	tag, err := q.db.Exec(ctx, "UPDATE jobs "+set+`
+		WHERE id = $1 AND status = 'running' AND locked_by = $2`, append([]any{job.ID, worker}, args...)...)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return errLeaseLost
+	}
+	return nil
+}
+
+func (q *Queue) logSaveError(job *Job, duration time.Duration, err error, msg string) {
+	if errors.Is(err, errLeaseLost) {
+		q.logJob(job, duration, nil).Warn("Job lease lost, result discarded")
+		return
+	}
+	q.logJob(job, duration, err).Error(msg)
+}
+
+func (q *Queue) run(ctx context.Context, job *Job) (err error) {
+	defer func() {
+		if p := recover(); p != nil {
+			err = fmt.Errorf("panic in job handler: %v", p)
+		}
+	}()
+	return q.handlers[job.Kind](ctx, job)
+}
+
+func (q *Queue) retryDelay(attempt int) time.Duration {
+	d := q.backoff << (attempt - 1)
+	if d <= 0 || d > q.maxBackoff {
+		d = q.maxBackoff
+	}
+	return d/2 + rand.N(d/2+1)
+}
+
+// rescue возвращает в очередь задачи, «зависшие» в статусе running после падения воркера.
+func (q *Queue) rescue(ctx context.Context) {
+	ticker := time.NewTicker(defaultRescuePeriod)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		tag, err := q.db.Exec(ctx, `
+			UPDATE jobs
+			SET status = CASE WHEN attempts >= max_attempts THEN 'dead' ELSE 'pending' END,
+			    finished_at = CASE WHEN attempts >= max_attempts THEN now() END,
+			    locked_at = NULL, locked_by = NULL, run_at = now(), last_error = 'job lock expired', updated_at = now()
+			WHERE status = 'running' AND locked_at < now() - $1::interval`, 2*q.jobTimeout)
+		if err != nil {
+			if ctx.Err() == nil {
+				q.logger.With(map[string]any{"error": err.Error()}).Error("Failed to rescue stuck jobs")
+			}
+			continue
+		}
+		if tag.RowsAffected() > 0 {
+			q.logger.With(map[string]any{"count": tag.RowsAffected()}).Warn("Rescued stuck jobs")
+		}
+	}
+}
+
+func (q *Queue) logJob(job *Job, duration time.Duration, err error) log.Logger {
+	fields := map[string]any{
+		"job_id":   job.ID,
+		"queue":    job.Queue,
+		"kind":     job.Kind,
+		"attempt":  job.Attempts,
+		"duration": duration.String(),
+	}
+	if err != nil {
+		fields["error"] = err.Error()
+	}
+	return q.logger.With(fields)
+}
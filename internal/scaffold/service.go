@@ -0,0 +1,344 @@
+// Package scaffold генерирует заготовки нового API-сервиса: proto, обработчик,
+// репозиторий, миграцию и подключение в cmd/app.
+package scaffold
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"github.com/desulaidovich/app/internal/migrator"
+)
+
+const (
+	mainFile = "cmd/app/main.go"
+	// servicesMarker — строка в app.WithServices, перед которой добавляются новые сервисы.
+	servicesMarker = "// tool generate service добавляет новые сервисы перед этой строкой."
+)
+
+var namePattern = regexp.MustCompile(`^[a-z][a-z0-9]*$`)
+
+// Service — параметры генерации сервиса.
+type Service struct {
+	// Name — имя сущности в единственном числе: order, invoice.
+	Name string
+	// Root — корень модуля, в котором лежит go.mod.
+	Root string
+}
+
+type file struct {
+	path string
+	tmpl *template.Template
+}
+
+type templateData struct {
+	Module      string
+	Name        string
+	Title       string
+	Plural      string
+	TitlePlural string
+}
+
+// Generate создаёт файлы сервиса и возвращает их пути. Существующие файлы не
+// перезаписываются: если хотя бы один уже есть, ничего не создаётся.
+func (s Service) Generate() ([]string, error) {
+	if !namePattern.MatchString(s.Name) || token.IsKeyword(s.Name) {
+		return nil, fmt.Errorf("invalid service name: %q (lowercase letters and digits, starting with a letter)", s.Name)
+	}
+
+	module, err := modulePath(filepath.Join(s.Root, "go.mod"))
+	if err != nil {
+		return nil, err
+	}
+
+	plural := pluralize(s.Name)
+	data := templateData{
+		Module:      module,
+		Name:        s.Name,
+		Title:       title(s.Name),
+		Plural:      plural,
+		TitlePlural: title(plural),
+	}
+
+	files := []file{
+		{filepath.Join("proto", s.Name, "v1", s.Name+".proto"), protoTemplate},
+		{filepath.Join("internal", s.Name, "repository.go"), repositoryTemplate},
+		{filepath.Join("internal", "handler", s.Name+".go"), handlerTemplate},
+	}
+
+	// Каталоги пакетов тоже проверяются: имя вроде queue попало бы в существующий пакет.
+	paths := []string{
+		filepath.Join("proto", s.Name),
+		filepath.Join("internal", s.Name),
+		filepath.Join("api", s.Name),
+	}
+	for _, f := range files {
+		paths = append(paths, f.path)
+	}
+	for _, p := range paths {
+		if _, err := os.Stat(filepath.Join(s.Root, p)); err == nil {
+			return nil, fmt.Errorf("%s already exists", p)
+		} else if !errors.Is(err, os.ErrNotExist) {
+			return nil, err
+		}
+	}
+
+	// Пакет репозитория импортируется в cmd/app/main.go и не должен совпадать с его переменными.
+	if declared, err := declares(filepath.Join(s.Root, mainFile), false, s.Name); err != nil {
+		return nil, err
+	} else if declared != "" {
+		return nil, fmt.Errorf("service name %q clashes with an identifier in %s", s.Name, mainFile)
+	}
+
+	// Обработчик добавляется в пакет handler: его объявления и импорты не должны совпадать
+	// с объявлениями остальных файлов пакета.
+	handlerFiles, err := filepath.Glob(filepath.Join(s.Root, "internal", "handler", "*.go"))
+	if err != nil {
+		return nil, err
+	}
+	for _, goFile := range handlerFiles {
+		if declared, err := declares(goFile, true, handlerIdents(data)...); err != nil {
+			return nil, err
+		} else if declared != "" {
+			return nil, fmt.Errorf("service name %q clashes with %s declared in %s", s.Name, declared, goFile)
+		}
+	}
+
+	if created, err := createsTable(filepath.Join(s.Root, migrator.Directory), plural); err != nil {
+		return nil, err
+	} else if created != "" {
+		return nil, fmt.Errorf("table %s is already created in %s", plural, created)
+	}
+
+	var created []string
+	for _, f := range files {
+		if err := write(filepath.Join(s.Root, f.path), f.tmpl, data); err != nil {
+			return created, err
+		}
+		created = append(created, f.path)
+	}
+
+	migration, err := migrator.CreateSQL(filepath.Join(s.Root, migrator.Directory), "create_"+plural,
+		fmt.Sprintf(migrationUp, plural), fmt.Sprintf(migrationDown, plural))
+	if err != nil {
+		return created, err
+	}
+	created = append(created, migration)
+
+	if err := wire(filepath.Join(s.Root, mainFile), data); err != nil {
+		return created, err
+	}
+	created = append(created, mainFile)
+
+	return created, nil
+}
+
+// wire добавляет сервис в app.WithServices в cmd/app/main.go перед строкой servicesMarker
+// и импортирует пакет репозитория.
+func wire(mainPath string, data templateData) error {
+	src, err := os.ReadFile(mainPath)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", mainPath, err)
+	}
+
+	lines := strings.Split(string(src), "\n")
+	idx := slices.IndexFunc(lines, func(line string) bool { return strings.TrimSpace(line) == servicesMarker })
+	if idx < 0 {
+		return fmt.Errorf("%s: line %q not found, add handler.New%sHandler(%s.NewRepository(txManager)) to app.WithServices manually",
+			mainPath, servicesMarker, data.Title, data.Name)
+	}
+	indent := lines[idx][:len(lines[idx])-len(strings.TrimLeft(lines[idx], "\t "))]
+	lines = slices.Insert(lines, idx, fmt.Sprintf("%shandler.New%sHandler(%s.NewRepository(txManager)),", indent, data.Title, data.Name))
+	src = []byte(strings.Join(lines, "\n"))
+
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, mainPath, src, parser.ImportsOnly)
+	if err != nil {
+		return fmt.Errorf("failed to parse %s: %w", mainPath, err)
+	}
+	if len(f.Imports) == 0 {
+		return fmt.Errorf("%s has no imports", mainPath)
+	}
+	offset := fset.Position(f.Imports[len(f.Imports)-1].End()).Offset
+	imp := fmt.Sprintf("\n\t%q", data.Module+"/internal/"+data.Name)
+	src = slices.Concat(src[:offset], []byte(imp), src[offset:])
+
+	formatted, err := format.Source(src)
+	if err != nil {
+		return fmt.Errorf("failed to format %s: %w", mainPath, err)
+	}
+	if err := os.WriteFile(mainPath, formatted, 0o644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", mainPath, err)
+	}
+	return nil
+}
+
+func write(dst string, tmpl *template.Template, data templateData) error {
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return fmt.Errorf("failed to render %s: %w", dst, err)
+	}
+
+	content := buf.Bytes()
+	if filepath.Ext(dst) == ".go" {
+		formatted, err := format.Source(content)
+		if err != nil {
+			return fmt.Errorf("failed to format %s: %w", dst, err)
+		}
+		content = formatted
+	}
+
+	if err := os.MkdirAll(filepath.Dir(dst), 0o755); err != nil {
+		return fmt.Errorf("failed to create directory for %s: %w", dst, err)
+	}
+	if err := os.WriteFile(dst, content, 0o644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", dst, err)
+	}
+	return nil
+}
+
+// declares возвращает первое из имён names, объявленное в файле goFile, или "".
+// С packageLevel учитываются только объявления уровня пакета и имена импортов,
+// иначе — любые объявления, включая локальные переменные и параметры.
+func declares(goFile string, packageLevel bool, names ...string) (string, error) {
+	f, err := parser.ParseFile(token.NewFileSet(), goFile, nil, parser.SkipObjectResolution)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse %s: %w", goFile, err)
+	}
+
+	var found string
+	match := func(idents ...*ast.Ident) {
+		for _, ident := range idents {
+			if found == "" && slices.Contains(names, ident.Name) {
+				found = ident.Name
+			}
+		}
+	}
+
+	for _, imp := range f.Imports {
+		if imp.Name != nil {
+			match(imp.Name)
+		} else if p, err := strconv.Unquote(imp.Path.Value); err == nil {
+			match(ast.NewIdent(path.Base(p)))
+		}
+	}
+
+	if packageLevel {
+		for _, decl := range f.Decls {
+			switch decl := decl.(type) {
+			case *ast.FuncDecl:
+				if decl.Recv == nil {
+					match(decl.Name)
+				}
+			case *ast.GenDecl:
+				for _, spec := range decl.Specs {
+					switch spec := spec.(type) {
+					case *ast.ValueSpec:
+						match(spec.Names...)
+					case *ast.TypeSpec:
+						match(spec.Name)
+					}
+				}
+			}
+		}
+		return found, nil
+	}
+
+	ast.Inspect(f, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.AssignStmt:
+			if n.Tok == token.DEFINE {
+				for _, expr := range n.Lhs {
+					if ident, ok := expr.(*ast.Ident); ok {
+						match(ident)
+					}
+				}
+			}
+		case *ast.ValueSpec:
+			match(n.Names...)
+		case *ast.TypeSpec:
+			match(n.Name)
+		case *ast.Field:
+			match(n.Names...)
+		case *ast.FuncDecl:
+			match(n.Name)
+		}
+		return found == ""
+	})
+	return found, nil
+}
+
+// createsTable возвращает миграцию из dir, которая создаёт таблицу table, или "".
+func createsTable(dir, table string) (string, error) {
+	entries, err := os.ReadDir(dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to read %s: %w", dir, err)
+	}
+
+	pattern := regexp.MustCompile(`(?i)\bcreate\s+(?:unlogged\s+)?table\s+(?:if\s+not\s+exists\s+)?(?:"?public"?\.)?"?` +
+		regexp.QuoteMeta(table) + `(?:"|\s|\(|$)`)
+	for _, entry := range entries {
+		if entry.IsDir() || !slices.Contains([]string{".sql", ".go"}, filepath.Ext(entry.Name())) {
+			continue
+		}
+		src, err := os.ReadFile(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			return "", fmt.Errorf("failed to read migration %s: %w", entry.Name(), err)
+		}
+		if pattern.Match(src) {
+			return filepath.Join(dir, entry.Name()), nil
+		}
+	}
+	return "", nil
+}
+
+func modulePath(goMod string) (string, error) {
+	f, err := os.Open(goMod)
+	if err != nil {
+		return "", fmt.Errorf("failed to open go.mod, run the command from the module root: %w", err)
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		if module, ok := strings.CutPrefix(strings.TrimSpace(scanner.Text()), "module "); ok {
+			return strings.TrimSpace(module), nil
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return "", err
+	}
+	return "", fmt.Errorf("no module directive in %s", goMod)
+}
+
+func title(s string) string {
+	return strings.ToUpper(s[:1]) + s[1:]
+}
+
+func pluralize(s string) string {
+	switch {
+	case strings.HasSuffix(s, "s"), strings.HasSuffix(s, "x"), strings.HasSuffix(s, "z"),
+		strings.HasSuffix(s, "ch"), strings.HasSuffix(s, "sh"):
+		return s + "es"
+	case strings.HasSuffix(s, "y") && len(s) > 1 && !strings.ContainsRune("aeiou", rune(s[len(s)-2])):
+		return s[:len(s)-1] + "ies"
+	default:
+		return s + "s"
+	}
+}
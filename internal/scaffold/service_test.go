@@ -0,0 +1,378 @@
+package scaffold
+
+import (
+	"bytes"
+	"flag"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "rewrite golden files in testdata/golden")
+
+func TestPluralize(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{in: "order", want: "orders"},
+		{in: "address", want: "addresses"},
+		{in: "box", want: "boxes"},
+		{in: "batch", want: "batches"},
+		{in: "wish", want: "wishes"},
+		{in: "company", want: "companies"},
+		{in: "key", want: "keys"},
+		{in: "y", want: "ys"},
+	}
+
+	for _, tt := range tests {
+		if got := pluralize(tt.in); got != tt.want {
+			t.Errorf("pluralize(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestDeclares(t *testing.T) {
+	src := `package handler
+
+import (
+	"context"
+
+	jobsv1 "example.com/app/api/jobs/v1"
+)
+
+const maxJobsPageSize = 500
+
+var errDenied error
+
+type JobsHandler struct {
+	queue string
+}
+
+func (h *JobsHandler) ListJobs(ctx context.Context, req *jobsv1.ListJobsRequest) error {
+	page := req.GetPageSize()
+	_ = page
+	return nil
+}
+
+func jobToProto(job *Job) *jobsv1.Job {
+	var out jobsv1.Job
+	return &out
+}
+`
+	goFile := filepath.Join(t.TempDir(), "jobs.go")
+	if err := os.WriteFile(goFile, []byte(src), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name         string
+		packageLevel bool
+		names        []string
+		want         string
+	}{
+		{name: "function", packageLevel: true, names: []string{"jobToProto"}, want: "jobToProto"},
+		{name: "constant", packageLevel: true, names: []string{"maxJobsPageSize"}, want: "maxJobsPageSize"},
+		{name: "variable", packageLevel: true, names: []string{"errDenied"}, want: "errDenied"},
+		{name: "type", packageLevel: true, names: []string{"JobsHandler"}, want: "JobsHandler"},
+		{name: "named import", packageLevel: true, names: []string{"jobsv1"}, want: "jobsv1"},
+		{name: "import base name", packageLevel: true, names: []string{"context"}, want: "context"},
+		{name: "first match", packageLevel: true, names: []string{"order", "errDenied", "jobToProto"}, want: "errDenied"},
+		{name: "method is not package level", packageLevel: true, names: []string{"ListJobs"}},
+		{name: "locals are not package level", packageLevel: true, names: []string{"page", "job", "out", "queue"}},
+		{name: "local variable", names: []string{"page"}, want: "page"},
+		{name: "parameter", names: []string{"job"}, want: "job"},
+		{name: "field", names: []string{"queue"}, want: "queue"},
+		{name: "var declaration", names: []string{"out"}, want: "out"},
+		{name: "not declared", names: []string{"order", "orderv1"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := declares(goFile, tt.packageLevel, tt.names...)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("declares = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCreatesTable(t *testing.T) {
+	tests := []struct {
+		name  string
+		src   string
+		table string
+		want  bool
+	}{
+		{name: "create table", src: "CREATE TABLE orders (\n    id BIGSERIAL\n);", table: "orders", want: true},
+		{name: "lowercase if not exists", src: "create table if not exists orders(id bigint);", table: "orders", want: true},
+		{name: "quoted with schema", src: `CREATE TABLE "public"."orders" (id BIGINT);`, table: "orders", want: true},
+		{name: "go migration", src: "tx.ExecContext(ctx, `CREATE TABLE orders (id BIGINT)`)", table: "orders", want: true},
+		{name: "longer name", src: "CREATE TABLE orders_archive (id BIGINT);", table: "orders"},
+		{name: "suffix match", src: "CREATE TABLE scheduled_tasks (id BIGINT);", table: "tasks"},
+		{name: "alter table", src: "ALTER TABLE orders ADD COLUMN note TEXT;", table: "orders"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			if err := os.WriteFile(filepath.Join(dir, "00001_init.sql"), []byte(tt.src), 0o644); err != nil {
+				t.Fatal(err)
+			}
+
+			got, err := createsTable(dir, tt.table)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if (got != "") != tt.want {
+				t.Errorf("createsTable = %q, want found %t", got, tt.want)
+			}
+		})
+	}
+
+	if got, err := createsTable(filepath.Join(t.TempDir(), "missing"), "orders"); got != "" || err != nil {
+		t.Errorf("missing directory: createsTable = %q, %v", got, err)
+	}
+}
+
+const mainSrc = `package main
+
+import (
+	"example.com/app/internal/app"
+	"example.com/app/internal/handler"
+)
+
+func main() {
+	_, _ = app.New(
+		app.WithServices(
+			handler.NewHealthHandler(),
+			` + servicesMarker + `
+		),
+	)
+}
+`
+
+func TestWire(t *testing.T) {
+	data := templateData{Module: "example.com/app", Name: "order", Title: "Order"}
+
+	tests := []struct {
+		name    string
+		src     string
+		want    []string
+		wantErr string
+	}{
+		{
+			name: "adds handler before marker and imports repository",
+			src:  mainSrc,
+			want: []string{
+				"\t\t\thandler.NewOrderHandler(order.NewRepository(txManager)),\n\t\t\t" + servicesMarker,
+				"\"example.com/app/internal/handler\"\n\t\"example.com/app/internal/order\"\n)",
+			},
+		},
+		{
+			name:    "missing marker",
+			src:     strings.Replace(mainSrc, servicesMarker, "", 1),
+			wantErr: "add handler.NewOrderHandler(order.NewRepository(txManager)) to app.WithServices manually",
+		},
+		{
+			name:    "no imports",
+			src:     "package main\n\nfunc main() {\n\t" + servicesMarker + "\n}\n",
+			wantErr: "has no imports",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mainPath := filepath.Join(t.TempDir(), "main.go")
+			if err := os.WriteFile(mainPath, []byte(tt.src), 0o644); err != nil {
+				t.Fatal(err)
+			}
+
+			err := wire(mainPath, data)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			got, err := os.ReadFile(mainPath)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(string(got), want) {
+					t.Errorf("main.go does not contain %q:\n%s", want, got)
+				}
+			}
+		})
+	}
+}
+
+// fixture создаёт минимальный модуль с cmd/app/main.go, пакетом handler и миграцией.
+func fixture(t *testing.T) string {
+	t.Helper()
+
+	root := t.TempDir()
+	files := map[string]string{
+		"go.mod":                     "module example.com/app\n",
+		mainFile:                     strings.Replace(mainSrc, "func main() {\n", "func main() {\n\tinvoice := 1\n\t_ = invoice\n", 1),
+		"internal/handler/jobs.go":   "package handler\n\nconst maxJobsPageSize = 500\n\nfunc jobToProto(job *Job) {}\n",
+		"internal/queue/queue.go":    "package queue\n",
+		"migrations/00001_users.sql": "-- +goose Up\nCREATE TABLE users (id BIGINT);\n",
+	}
+	for name, src := range files {
+		p := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(src), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return root
+}
+
+func TestGenerateRejects(t *testing.T) {
+	tests := []struct {
+		name    string
+		service string
+		wantErr string
+	}{
+		{name: "invalid name", service: "Order", wantErr: "invalid service name"},
+		{name: "keyword", service: "func", wantErr: "invalid service name"},
+		{name: "existing package", service: "queue", wantErr: "internal/queue already exists"},
+		{name: "identifier in main.go", service: "invoice", wantErr: `clashes with an identifier in cmd/app/main.go`},
+		{name: "declaration in package handler", service: "job", wantErr: "clashes with maxJobsPageSize declared in"},
+		{name: "existing table", service: "user", wantErr: "table users is already created in"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := fixture(t)
+
+			created, err := Service{Name: tt.service, Root: root}.Generate()
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("error = %v, want %q", err, tt.wantErr)
+			}
+			if len(created) > 0 {
+				t.Errorf("created %v before failing", created)
+			}
+			if _, err := os.Stat(filepath.Join(root, "proto")); !os.IsNotExist(err) {
+				t.Errorf("proto directory was created")
+			}
+		})
+	}
+}
+
+// TestGenerateGolden генерирует сервис order в копии модуля, сравнивает файлы с
+// testdata/golden и проверяет результат go vet. api/order/v1 подменяется заглушкой
+// из testdata, потому что make proto в тестах недоступен.
+func TestGenerateGolden(t *testing.T) {
+	if testing.Short() {
+		t.Skip("copies the module and runs go vet")
+	}
+	goBin, err := exec.LookPath("go")
+	if err != nil {
+		t.Skip("go command not found")
+	}
+
+	root := t.TempDir()
+	copyModule(t, filepath.Join("..", ".."), root)
+
+	created, err := Service{Name: "order", Root: root}.Generate()
+	if err != nil {
+		t.Fatalf("Generate: %v", err)
+	}
+
+	golden := map[string]string{
+		filepath.Join("proto", "order", "v1", "order.proto"): "order.proto",
+		filepath.Join("internal", "order", "repository.go"):  "repository.go",
+		filepath.Join("internal", "handler", "order.go"):     "handler.go",
+	}
+	for _, p := range created {
+		if strings.HasPrefix(p, filepath.Join(root, "migrations")) {
+			golden[p] = "create_orders.sql"
+		}
+	}
+	if len(golden) != 4 {
+		t.Fatalf("created = %v, want a create_orders migration", created)
+	}
+	for p, name := range golden {
+		if !filepath.IsAbs(p) {
+			p = filepath.Join(root, p)
+		}
+		assertGolden(t, p, filepath.Join("testdata", "golden", name+".golden"))
+	}
+
+	copyModule(t, filepath.Join("testdata", "api"), filepath.Join(root, "api"))
+
+	cmd := exec.Command(goBin, "vet", "./internal/order", "./internal/handler", "./cmd/app")
+	cmd.Dir = root
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("go vet: %v\n%s", err, out)
+	}
+}
+
+func assertGolden(t *testing.T, path, goldenPath string) {
+	t.Helper()
+
+	got, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *update {
+		if err := os.WriteFile(goldenPath, got, 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+
+	want, err := os.ReadFile(goldenPath)
+	if err != nil {
+		t.Fatalf("%v (run go test -update to create it)", err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("%s differs from %s:\n%s", path, goldenPath, got)
+	}
+}
+
+// copyModule копирует дерево src в dst без каталога .git.
+func copyModule(t *testing.T, src, dst string) {
+	t.Helper()
+
+	err := filepath.WalkDir(src, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(src, p)
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if d.Name() == ".git" {
+				return filepath.SkipDir
+			}
+			return os.MkdirAll(filepath.Join(dst, rel), 0o755)
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		data, err := os.ReadFile(p)
+		if err != nil {
+			return err
+		}
+		return os.WriteFile(filepath.Join(dst, rel), data, 0o644)
+	})
+	if err != nil {
+		t.Fatalf("failed to copy %s: %v", src, err)
+	}
+}
@@ -0,0 +1,253 @@
+package scaffold
+
+import "text/template"
+
+const migrationUp = `CREATE TABLE %s (
+    id         BIGSERIAL PRIMARY KEY,
+    name       TEXT        NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);`
+
+const migrationDown = `DROP TABLE %s;`
+
+var protoTemplate = template.Must(template.New("proto").Parse(`syntax = "proto3";
+
+package {{.Name}}.v1;
+
+import "google/api/annotations.proto";
+import "google/protobuf/timestamp.proto";
+
+option go_package = "{{.Module}}/api/{{.Name}}/v1;{{.Name}}v1";
+
+service {{.Title}}Service {
+  rpc List{{.TitlePlural}}(List{{.TitlePlural}}Request) returns (List{{.TitlePlural}}Response) {
+    option (google.api.http) = {
+      get: "/v1/{{.Plural}}"
+    };
+  }
+
+  rpc Get{{.Title}}(Get{{.Title}}Request) returns ({{.Title}}) {
+    option (google.api.http) = {
+      get: "/v1/{{.Plural}}/{id}"
+    };
+  }
+
+  rpc Create{{.Title}}(Create{{.Title}}Request) returns ({{.Title}}) {
+    option (google.api.http) = {
+      post: "/v1/{{.Plural}}"
+      body: "*"
+    };
+  }
+}
+
+message {{.Title}} {
+  int64 id = 1;
+  string name = 2;
+  google.protobuf.Timestamp created_at = 3;
+  google.protobuf.Timestamp updated_at = 4;
+}
+
+message List{{.TitlePlural}}Request {
+  int32 page_size = 1;
+  int64 after_id = 2;
+}
+
+message List{{.TitlePlural}}Response {
+  repeated {{.Title}} {{.Plural}} = 1;
+  int64 next_after_id = 2;
+}
+
+message Get{{.Title}}Request {
+  int64 id = 1;
+}
+
+message Create{{.Title}}Request {
+  string name = 1;
+}
+`))
+
+var repositoryTemplate = template.Must(template.New("repository").Parse(`package {{.Name}}
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"{{.Module}}/internal/postgres"
+)
+
+const defaultListLimit = 50
+
+const columns = "id, name, created_at, updated_at"
+
+type {{.Title}} struct {
+	ID        int64
+	Name      string
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+func scan(row pgx.Row) (*{{.Title}}, error) {
+	var v {{.Title}}
+	if err := row.Scan(&v.ID, &v.Name, &v.CreatedAt, &v.UpdatedAt); err != nil {
+		return nil, err
+	}
+	return &v, nil
+}
+
+// Repository выполняет запросы в транзакции TxManager из контекста, если она есть.
+type Repository struct {
+	tx *postgres.TxManager
+}
+
+func NewRepository(tx *postgres.TxManager) *Repository {
+	return &Repository{tx: tx}
+}
+
+// List возвращает записи по возрастанию id, начиная после afterID.
+func (r *Repository) List(ctx context.Context, limit int, afterID int64) ([]*{{.Title}}, error) {
+	if limit <= 0 {
+		limit = defaultListLimit
+	}
+
+	rows, err := r.tx.Reader(ctx).Query(ctx,
+		"SELECT "+columns+" FROM {{.Plural}} WHERE id > $1 ORDER BY id LIMIT $2", afterID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list {{.Plural}}: %w", err)
+	}
+	defer rows.Close()
+
+	var items []*{{.Title}}
+	for rows.Next() {
+		v, err := scan(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan {{.Name}}: %w", err)
+		}
+		items = append(items, v)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list {{.Plural}}: %w", err)
+	}
+
+	return items, nil
+}
+
+func (r *Repository) Get(ctx context.Context, id int64) (*{{.Title}}, error) {
+	v, err := scan(r.tx.Reader(ctx).QueryRow(ctx, "SELECT "+columns+" FROM {{.Plural}} WHERE id = $1", id))
+	if err != nil {
+		return nil, fmt.Errorf("{{.Name}} %d: %w", id, postgres.Classify(err))
+	}
+	return v, nil
+}
+
+func (r *Repository) Create(ctx context.Context, name string) (*{{.Title}}, error) {
+	v, err := scan(r.tx.Querier(ctx).QueryRow(ctx, "INSERT INTO {{.Plural}} (name) VALUES ($1) RETURNING "+columns, name))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create {{.Name}}: %w", postgres.Classify(err))
+	}
+	return v, nil
+}
+`))
+
+var handlerTemplate = template.Must(template.New("handler").Parse(`package handler
+
+import (
+	"context"
+
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	{{.Name}}v1 "{{.Module}}/api/{{.Name}}/v1"
+	"{{.Module}}/internal/{{.Name}}"
+)
+
+const max{{.TitlePlural}}PageSize = 500
+
+type {{.Title}}Handler struct {
+	{{.Name}}v1.Unimplemented{{.Title}}ServiceServer
+	repo *{{.Name}}.Repository
+}
+
+func New{{.Title}}Handler(repo *{{.Name}}.Repository) *{{.Title}}Handler {
+	return &{{.Title}}Handler{repo: repo}
+}
+
+func (h *{{.Title}}Handler) Name() string {
+	return "{{.Name}}"
+}
+
+func (h *{{.Title}}Handler) RegisterGRPC(s *grpc.Server) {
+	{{.Name}}v1.Register{{.Title}}ServiceServer(s, h)
+}
+
+func (h *{{.Title}}Handler) RegisterGateway(ctx context.Context, mux *runtime.ServeMux) error {
+	return {{.Name}}v1.Register{{.Title}}ServiceHandlerServer(ctx, mux, h)
+}
+
+func (h *{{.Title}}Handler) List{{.TitlePlural}}(ctx context.Context, req *{{.Name}}v1.List{{.TitlePlural}}Request) (*{{.Name}}v1.List{{.TitlePlural}}Response, error) {
+	if req.GetPageSize() < 0 || req.GetPageSize() > max{{.TitlePlural}}PageSize {
+		return nil, status.Errorf(codes.InvalidArgument, "page_size must be between 0 and %d", max{{.TitlePlural}}PageSize)
+	}
+
+	items, err := h.repo.List(ctx, int(req.GetPageSize()), req.GetAfterId())
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &{{.Name}}v1.List{{.TitlePlural}}Response{ {{- .TitlePlural}}: make([]*{{.Name}}v1.{{.Title}}, 0, len(items))}
+	for _, item := range items {
+		resp.{{.TitlePlural}} = append(resp.{{.TitlePlural}}, {{.Name}}ServiceToProto(item))
+	}
+	if len(items) > 0 {
+		resp.NextAfterId = items[len(items)-1].ID
+	}
+	return resp, nil
+}
+
+func (h *{{.Title}}Handler) Get{{.Title}}(ctx context.Context, req *{{.Name}}v1.Get{{.Title}}Request) (*{{.Name}}v1.{{.Title}}, error) {
+	item, err := h.repo.Get(ctx, req.GetId())
+	if err != nil {
+		return nil, err
+	}
+	return {{.Name}}ServiceToProto(item), nil
+}
+
+func (h *{{.Title}}Handler) Create{{.Title}}(ctx context.Context, req *{{.Name}}v1.Create{{.Title}}Request) (*{{.Name}}v1.{{.Title}}, error) {
+	if req.GetName() == "" {
+		return nil, status.Error(codes.InvalidArgument, "name is required")
+	}
+
+	item, err := h.repo.Create(ctx, req.GetName())
+	if err != nil {
+		return nil, err
+	}
+	return {{.Name}}ServiceToProto(item), nil
+}
+
+func {{.Name}}ServiceToProto(item *{{.Name}}.{{.Title}}) *{{.Name}}v1.{{.Title}} {
+	return &{{.Name}}v1.{{.Title}}{
+		Id:        item.ID,
+		Name:      item.Name,
+		CreatedAt: timestamppb.New(item.CreatedAt),
+		UpdatedAt: timestamppb.New(item.UpdatedAt),
+	}
+}
+`))
+
+// handlerIdents — имена, которые handlerTemplate объявляет и импортирует в пакете handler.
+func handlerIdents(data templateData) []string {
+	return []string{
+		data.Title + "Handler",
+		"New" + data.Title + "Handler",
+		"max" + data.TitlePlural + "PageSize",
+		data.Name + "ServiceToProto",
+		data.Name,
+		data.Name + "v1",
+	}
+}
@@ -0,0 +1,57 @@
+// Package orderv1 — упрощённая замена кода, который make proto генерирует из
+// proto/order/v1/order.proto: ровно то, чем пользуется сгенерированный обработчик.
+package orderv1
+
+import (
+	"context"
+
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+type Order struct {
+	Id        int64
+	Name      string
+	CreatedAt *timestamppb.Timestamp
+	UpdatedAt *timestamppb.Timestamp
+}
+
+type ListOrdersRequest struct {
+	PageSize int32
+	AfterId  int64
+}
+
+func (x *ListOrdersRequest) GetPageSize() int32 { return x.PageSize }
+func (x *ListOrdersRequest) GetAfterId() int64  { return x.AfterId }
+
+type ListOrdersResponse struct {
+	Orders      []*Order
+	NextAfterId int64
+}
+
+type GetOrderRequest struct {
+	Id int64
+}
+
+func (x *GetOrderRequest) GetId() int64 { return x.Id }
+
+type CreateOrderRequest struct {
+	Name string
+}
+
+func (x *CreateOrderRequest) GetName() string { return x.Name }
+
+type OrderServiceServer interface {
+	ListOrders(context.Context, *ListOrdersRequest) (*ListOrdersResponse, error)
+	GetOrder(context.Context, *GetOrderRequest) (*Order, error)
+	CreateOrder(context.Context, *CreateOrderRequest) (*Order, error)
+}
+
+type UnimplementedOrderServiceServer struct{}
+
+func RegisterOrderServiceServer(grpc.ServiceRegistrar, OrderServiceServer) {}
+
+func RegisterOrderServiceHandlerServer(context.Context, *runtime.ServeMux, OrderServiceServer) error {
+	return nil
+}
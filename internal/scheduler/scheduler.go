@@ -0,0 +1,442 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/robfig/cron/v3"
+
+	"github.com/desulaidovich/app/internal/postgres"
+	"github.com/desulaidovich/app/pkg/log"
+)
+
+const (
+	defaultTickInterval = 1 * time.Second
+	defaultTaskTimeout  = 1 * time.Hour
+)
+
+// TaskFunc — периодическая работа. Контекст отменяется по таймауту задачи
+// или при остановке планировщика.
+type TaskFunc func(ctx context.Context) error
+
+type task struct {
+	name       string
+	spec       string
+	schedule   cron.Schedule
+	fn         TaskFunc
+	leaderOnly bool
+	timeout    time.Duration
+
+	running atomic.Bool
+	// skipped — последний слот, пропущенный из-за незавершённого запуска; только для dispatch.
+	skipped time.Time
+}
+
+type TaskOption func(*task) error
+
+// LeaderOnly запускает задачу только на экземпляре, выбранном лидером.
+// Требует WithElector.
+func LeaderOnly() TaskOption {
+	return func(t *task) error {
+		t.leaderOnly = true
+		return nil
+	}
+}
+
+func WithTaskTimeout(timeout time.Duration) TaskOption {
+	return func(t *task) error {
+		if timeout <= 0 {
+			return errors.New("task timeout must be positive")
+		}
+		t.timeout = timeout
+		return nil
+	}
+}
+
+// Every возвращает расписание запуска с интервалом d.
+func Every(d time.Duration) string {
+	return "@every " + d.String()
+}
+
+// Scheduler запускает зарегистрированные задачи по расписанию и хранит их состояние
+// в таблице scheduled_tasks. Время следующего запуска хранится в таблице и занимается
+// атомарно, поэтому каждый слот выполняется один раз, а параллельный запуск исключён
+// advisory-блокировкой. Планировщик можно запускать на всех экземплярах.
+// Реализует runner.Handler.
+type Scheduler struct {
+	db      *postgres.Pool
+	logger  log.Logger
+	elector *postgres.Elector
+	tick    time.Duration
+
+	mu      sync.Mutex
+	tasks   []*task
+	started bool
+	cancel  context.CancelFunc
+	abort   context.CancelFunc
+	wg      sync.WaitGroup
+}
+
+type Option func(*Scheduler) error
+
+func WithLogger(logger log.Logger) Option {
+	return func(s *Scheduler) error {
+		if logger == nil {
+			return errors.New("logger cannot be nil")
+		}
+		s.logger = logger
+		return nil
+	}
+}
+
+// WithElector включает запуск задач LeaderOnly на лидере.
+func WithElector(elector *postgres.Elector) Option {
+	return func(s *Scheduler) error {
+		if elector == nil {
+			return errors.New("elector cannot be nil")
+		}
+		s.elector = elector
+		return nil
+	}
+}
+
+// WithTickInterval задаёт, как часто планировщик проверяет расписание и команды из API.
+func WithTickInterval(interval time.Duration) Option {
+	return func(s *Scheduler) error {
+		if interval <= 0 {
+			return errors.New("tickInterval must be positive")
+		}
+		s.tick = interval
+		return nil
+	}
+}
+
+func New(db *postgres.Pool, opts ...Option) (*Scheduler, error) {
+	if db == nil {
+		return nil, errors.New("postgres pool cannot be nil")
+	}
+
+	s := &Scheduler{db: db, tick: defaultTickInterval}
+	for _, opt := range opts {
+		if err := opt(s); err != nil {
+			return nil, fmt.Errorf("scheduler option: %w", err)
+		}
+	}
+	if s.logger == nil {
+		return nil, errors.New("logger is required")
+	}
+
+	return s, nil
+}
+
+// Register добавляет задачу. spec — cron-выражение из пяти полей, дескриптор
+// вида @hourly или интервал @every 5m (см. Every). Регистрация выполняется до Start.
+func (s *Scheduler) Register(name, spec string, fn TaskFunc, opts ...TaskOption) error {
+	if name == "" {
+		return errors.New("task name cannot be empty")
+	}
+	if fn == nil {
+		return errors.New("task func cannot be nil")
+	}
+
+	schedule, err := cron.ParseStandard(spec)
+	if err != nil {
+		return fmt.Errorf("invalid schedule %q for task %s: %w", spec, name, err)
+	}
+
+	t := &task{name: name, spec: spec, schedule: schedule, fn: fn, timeout: defaultTaskTimeout}
+	for _, opt := range opts {
+		if err := opt(t); err != nil {
+			return fmt.Errorf("task %s: %w", name, err)
+		}
+	}
+	if t.leaderOnly && s.elector == nil {
+		return fmt.Errorf("task %s: leader-only tasks require an elector", name)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.started {
+		return errors.New("scheduler already started")
+	}
+	for _, existing := range s.tasks {
+		if existing.name == name {
+			return fmt.Errorf("task %s already registered", name)
+		}
+	}
+	s.tasks = append(s.tasks, t)
+	return nil
+}
+
+func (s *Scheduler) Start(ctx context.Context) error {
+	s.mu.Lock()
+	if s.started {
+		s.mu.Unlock()
+		return errors.New("scheduler already started")
+	}
+	s.started = true
+	// Выполняющиеся задачи не отменяются вместе с ctx: Stop даёт им завершиться.
+	taskCtx, abort := context.WithCancel(context.WithoutCancel(ctx))
+	ctx, s.cancel = context.WithCancel(ctx)
+	s.abort = abort
+	s.mu.Unlock()
+
+	if err := s.sync(ctx); err != nil {
+		return err
+	}
+
+	ticker := time.NewTicker(s.tick)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case now := <-ticker.C:
+			s.dispatch(ctx, taskCtx, now)
+		}
+	}
+}
+
+// Stop прекращает запуск новых задач и ждёт завершения выполняющихся.
+// Если ctx истекает раньше, контексты задач отменяются.
+func (s *Scheduler) Stop(ctx context.Context) error {
+	s.mu.Lock()
+	cancel, abort := s.cancel, s.abort
+	s.mu.Unlock()
+
+	if cancel == nil {
+		return nil
+	}
+	cancel()
+
+	done := make(chan struct{})
+	go func() {
+		s.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		abort()
+		return nil
+	case <-ctx.Done():
+		abort()
+		<-done
+		return fmt.Errorf("scheduler stop: %w", ctx.Err())
+	}
+}
+
+// sync сохраняет зарегистрированные задачи в таблицу и вычисляет время первого запуска.
+// Будущий слот, уже записанный другим экземпляром с тем же расписанием, сохраняется.
+func (s *Scheduler) sync(ctx context.Context) error {
+	now := time.Now()
+	for _, t := range s.tasks {
+		if _, err := s.db.Exec(ctx, `
+			INSERT INTO scheduled_tasks (name, schedule, leader_only, next_run_at)
+			VALUES ($1, $2, $3, $4)
+			ON CONFLICT (name) DO UPDATE
+			SET schedule = EXCLUDED.schedule, leader_only = EXCLUDED.leader_only,
+			    next_run_at = CASE
+			        WHEN scheduled_tasks.schedule = EXCLUDED.schedule AND scheduled_tasks.next_run_at > now()
+			        THEN scheduled_tasks.next_run_at
+			        ELSE EXCLUDED.next_run_at
+			    END,
+			    updated_at = now()`,
+			t.name, t.spec, t.leaderOnly, t.schedule.Next(now),
+		); err != nil {
+			return fmt.Errorf("failed to register task %s: %w", t.name, err)
+		}
+	}
+
+	s.logger.With(map[string]any{
+		"tasks": len(s.tasks),
+	}).Info("Scheduler started")
+	return nil
+}
+
+// dispatch запускает задачи, время которых подошло или запуск которых запрошен через API.
+// ctx отменяется при остановке планировщика, taskCtx — контекст самих задач, который
+// Stop отменяет, только если не дождался их завершения.
+func (s *Scheduler) dispatch(ctx, taskCtx context.Context, now time.Time) {
+	states, err := s.states(ctx)
+	if err != nil {
+		if ctx.Err() == nil {
+			s.logger.With(map[string]any{"error": err.Error()}).Error("Failed to load task states")
+		}
+		return
+	}
+
+	for _, t := range s.tasks {
+		state, ok := states[t.name]
+		if !ok {
+			continue
+		}
+		d := decide(state, now, !t.leaderOnly || s.elector.IsLeader())
+		if d.skip != nil {
+			s.skip(ctx, t, *d.skip)
+		}
+		if !d.run {
+			continue
+		}
+		slot := d.slot
+		if !t.running.CompareAndSwap(false, true) {
+			// Слот остаётся в таблице, пока текущий запуск не запишет результат и не сдвинет его,
+			// запрошенный запуск дождётся завершения текущего.
+			if slot != nil && !slot.Equal(t.skipped) {
+				t.skipped = *slot
+				s.logger.With(map[string]any{"task": t.name}).Warn("Task is still running, skipping")
+			}
+			continue
+		}
+
+		s.wg.Go(func() {
+			defer t.running.Store(false)
+			s.run(ctx, taskCtx, t, slot, state.runRequested)
+		})
+	}
+}
+
+// decision — что dispatch делает с задачей на одном тике.
+type decision struct {
+	// slot — наступивший слот расписания, который должен занять запуск.
+	slot *time.Time
+	// skip — наступивший слот приостановленной задачи, который сдвигается без запуска.
+	skip *time.Time
+	// run — задачу нужно запустить по слоту или по запросу из API.
+	run bool
+}
+
+// decide решает по состоянию задачи в таблице, что делать с ней на тике now.
+// eligible ложно для задач LeaderOnly на экземпляре, который не лидер.
+func decide(state taskState, now time.Time, eligible bool) decision {
+	slot := state.nextRunAt
+	if slot != nil && now.Before(*slot) {
+		slot = nil
+	}
+	if !eligible || (slot == nil && !state.runRequested) {
+		return decision{}
+	}
+	if state.paused {
+		// Запрошенный запуск выполняется и у приостановленной задачи, но слот не занимает.
+		return decision{skip: slot, run: state.runRequested}
+	}
+	return decision{slot: slot, run: true}
+}
+
+type taskState struct {
+	paused       bool
+	runRequested bool
+	nextRunAt    *time.Time
+}
+
+func (s *Scheduler) states(ctx context.Context) (map[string]taskState, error) {
+	rows, err := s.db.Query(ctx, "SELECT name, paused, run_requested, next_run_at FROM scheduled_tasks")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	states := make(map[string]taskState)
+	for rows.Next() {
+		var (
+			name  string
+			state taskState
+		)
+		if err := rows.Scan(&name, &state.paused, &state.runRequested, &state.nextRunAt); err != nil {
+			return nil, err
+		}
+		states[name] = state
+	}
+	return states, rows.Err()
+}
+
+// skip сдвигает пропущенный слот приостановленной задачи, чтобы после Resume
+// она не запустилась сразу.
+func (s *Scheduler) skip(ctx context.Context, t *task, slot time.Time) {
+	if _, err := s.db.Exec(ctx, `
+		UPDATE scheduled_tasks
+		SET next_run_at = $3, updated_at = now()
+		WHERE name = $1 AND next_run_at = $2 AND paused`,
+		t.name, slot, t.schedule.Next(time.Now()),
+	); err != nil && ctx.Err() == nil {
+		s.logger.With(map[string]any{"task": t.name, "error": err.Error()}).Error("Failed to skip task slot")
+	}
+}
+
+// run выполняет задачу под advisory-блокировкой, исключающей параллельный запуск
+// на других экземплярах, и записывает результат. Запуск выполняется, только если
+// этому экземпляру удалось занять слот slot или запрос из API: другие экземпляры,
+// увидевшие тот же слот, получат ноль изменённых строк.
+func (s *Scheduler) run(ctx, taskCtx context.Context, t *task, slot *time.Time, requested bool) {
+	conn, err := s.db.Acquire(ctx)
+	if err != nil {
+		s.logger.With(map[string]any{"task": t.name, "error": err.Error()}).Error("Failed to acquire connection")
+		return
+	}
+	defer conn.Release()
+
+	key := postgres.AdvisoryLockKey("scheduler:" + t.name)
+	var locked bool
+	if err := conn.QueryRow(ctx, "SELECT pg_try_advisory_lock($1)", key).Scan(&locked); err != nil || !locked {
+		return
+	}
+	defer func() {
+		_, _ = conn.Exec(context.WithoutCancel(ctx), "SELECT pg_advisory_unlock($1)", key)
+	}()
+
+	tag, err := conn.Exec(ctx, `
+		UPDATE scheduled_tasks
+		SET run_requested = run_requested AND NOT $4,
+		    next_run_at = CASE WHEN next_run_at = $2 THEN $3 ELSE next_run_at END,
+		    last_started_at = now(), updated_at = now()
+		WHERE name = $1 AND (next_run_at = $2 OR (run_requested AND $4))`,
+		t.name, slot, t.schedule.Next(time.Now()), requested,
+	)
+	if err != nil {
+		s.logger.With(map[string]any{"task": t.name, "error": err.Error()}).Error("Failed to record task start")
+		return
+	}
+	if tag.RowsAffected() == 0 {
+		return
+	}
+
+	taskCtx, cancel := context.WithTimeout(taskCtx, t.timeout)
+	start := time.Now()
+	err = call(taskCtx, t.fn)
+	duration := time.Since(start)
+	cancel()
+
+	status, lastError := StatusSucceeded, ""
+	logger := s.logger.With(map[string]any{"task": t.name, "duration": duration.String()})
+	if err != nil {
+		status, lastError = StatusFailed, err.Error()
+		logger.With(map[string]any{"error": lastError}).Error("Task failed")
+	} else {
+		logger.Info("Task succeeded")
+	}
+
+	if _, err := conn.Exec(context.WithoutCancel(ctx), `
+		UPDATE scheduled_tasks
+		SET last_finished_at = now(), last_status = $2, last_error = NULLIF($3, ''),
+		    last_duration_ms = $4, next_run_at = $5, updated_at = now()
+		WHERE name = $1`,
+		t.name, status, lastError, duration.Milliseconds(), t.schedule.Next(time.Now()),
+	); err != nil {
+		logger.With(map[string]any{"error": err.Error()}).Error("Failed to record task result")
+	}
+}
+
+func call(ctx context.Context, fn TaskFunc) (err error) {
+	defer func() {
+		if p := recover(); p != nil {
+			err = fmt.Errorf("panic in task: %v", p)
+		}
+	}()
+	return fn(ctx)
+}
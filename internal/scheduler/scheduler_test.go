@@ -0,0 +1,196 @@
+package scheduler
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/desulaidovich/app/internal/postgres"
+)
+
+func TestDecide(t *testing.T) {
+	now := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+	past := now.Add(-time.Minute)
+	future := now.Add(time.Minute)
+
+	tests := []struct {
+		name     string
+		state    taskState
+		eligible bool
+		want     decision
+	}{
+		{name: "slot not reached", state: taskState{nextRunAt: &future}, eligible: true},
+		{name: "no slot", state: taskState{}, eligible: true},
+		{name: "slot reached", state: taskState{nextRunAt: &past}, eligible: true, want: decision{slot: &past, run: true}},
+		{name: "slot reached exactly", state: taskState{nextRunAt: &now}, eligible: true, want: decision{slot: &now, run: true}},
+		{
+			name:     "requested before slot",
+			state:    taskState{nextRunAt: &future, runRequested: true},
+			eligible: true,
+			want:     decision{run: true},
+		},
+		{
+			name:     "requested with slot reached",
+			state:    taskState{nextRunAt: &past, runRequested: true},
+			eligible: true,
+			want:     decision{slot: &past, run: true},
+		},
+		{name: "paused skips slot", state: taskState{nextRunAt: &past, paused: true}, eligible: true, want: decision{skip: &past}},
+		{
+			name:     "paused runs request without slot",
+			state:    taskState{nextRunAt: &past, paused: true, runRequested: true},
+			eligible: true,
+			want:     decision{skip: &past, run: true},
+		},
+		{name: "paused before slot", state: taskState{nextRunAt: &future, paused: true}, eligible: true},
+		{name: "not leader", state: taskState{nextRunAt: &past, runRequested: true}, eligible: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := decide(tt.state, now, tt.eligible)
+			if got.run != tt.want.run || !sameTime(got.slot, tt.want.slot) || !sameTime(got.skip, tt.want.skip) {
+				t.Errorf("decide = {slot: %v, skip: %v, run: %t}, want {slot: %v, skip: %v, run: %t}",
+					got.slot, got.skip, got.run, tt.want.slot, tt.want.skip, tt.want.run)
+			}
+		})
+	}
+}
+
+func sameTime(a, b *time.Time) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.Equal(*b)
+}
+
+func TestSchedule(t *testing.T) {
+	from := time.Date(2026, 10, 18, 12, 7, 30, 0, time.UTC)
+
+	tests := []struct {
+		spec string
+		want time.Time
+	}{
+		{spec: Every(5 * time.Minute), want: from.Add(5 * time.Minute)},
+		{spec: "@hourly", want: time.Date(2026, 10, 18, 13, 0, 0, 0, time.UTC)},
+		{spec: "0 3 * * *", want: time.Date(2026, 10, 19, 3, 0, 0, 0, time.UTC)},
+		{spec: "*/15 * * * *", want: time.Date(2026, 10, 18, 12, 15, 0, 0, time.UTC)},
+		{spec: "0 9 * * MON", want: time.Date(2026, 10, 19, 9, 0, 0, 0, time.UTC)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			s := &Scheduler{}
+			if err := s.Register("task", tt.spec, noop); err != nil {
+				t.Fatal(err)
+			}
+			if got := s.tasks[0].schedule.Next(from); !got.Equal(tt.want) {
+				t.Errorf("Next(%v) = %v, want %v", from, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEvery(t *testing.T) {
+	tests := []struct {
+		d    time.Duration
+		want string
+	}{
+		{d: 5 * time.Minute, want: "@every 5m0s"},
+		{d: 90 * time.Second, want: "@every 1m30s"},
+		{d: 2 * time.Hour, want: "@every 2h0m0s"},
+	}
+
+	for _, tt := range tests {
+		if got := Every(tt.d); got != tt.want {
+			t.Errorf("Every(%v) = %q, want %q", tt.d, got, tt.want)
+		}
+	}
+}
+
+func TestRegister(t *testing.T) {
+	tests := []struct {
+		name      string
+		scheduler *Scheduler
+		task      string
+		spec      string
+		fn        TaskFunc
+		opts      []TaskOption
+		wantErr   string
+	}{
+		{name: "valid", scheduler: &Scheduler{}, task: "cleanup", spec: "@daily", fn: noop},
+		{
+			name:      "leader only with elector",
+			scheduler: &Scheduler{elector: &postgres.Elector{}},
+			task:      "cleanup",
+			spec:      "@daily",
+			fn:        noop,
+			opts:      []TaskOption{LeaderOnly()},
+		},
+		{name: "empty name", scheduler: &Scheduler{}, spec: "@daily", fn: noop, wantErr: "task name cannot be empty"},
+		{name: "nil func", scheduler: &Scheduler{}, task: "cleanup", spec: "@daily", wantErr: "task func cannot be nil"},
+		{
+			name:      "invalid spec",
+			scheduler: &Scheduler{},
+			task:      "cleanup",
+			spec:      "every day",
+			fn:        noop,
+			wantErr:   `invalid schedule "every day" for task cleanup`,
+		},
+		{
+			name:      "invalid timeout",
+			scheduler: &Scheduler{},
+			task:      "cleanup",
+			spec:      "@daily",
+			fn:        noop,
+			opts:      []TaskOption{WithTaskTimeout(0)},
+			wantErr:   "task cleanup: task timeout must be positive",
+		},
+		{
+			name:      "leader only without elector",
+			scheduler: &Scheduler{},
+			task:      "cleanup",
+			spec:      "@daily",
+			fn:        noop,
+			opts:      []TaskOption{LeaderOnly()},
+			wantErr:   "task cleanup: leader-only tasks require an elector",
+		},
+		{
+			name:      "duplicate name",
+			scheduler: &Scheduler{tasks: []*task{{name: "cleanup"}}},
+			task:      "cleanup",
+			spec:      "@daily",
+			fn:        noop,
+			wantErr:   "task cleanup already registered",
+		},
+		{
+			name:      "after start",
+			scheduler: &Scheduler{started: true},
+			task:      "cleanup",
+			spec:      "@daily",
+			fn:        noop,
+			wantErr:   "scheduler already started",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.scheduler.Register(tt.task, tt.spec, tt.fn, tt.opts...)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				if last := tt.scheduler.tasks[len(tt.scheduler.tasks)-1]; last.timeout != defaultTaskTimeout {
+					t.Errorf("timeout = %v, want %v", last.timeout, defaultTaskTimeout)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func noop(context.Context) error { return nil }
@@ -0,0 +1,95 @@
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/desulaidovich/app/internal/postgres"
+)
+
+const (
+	StatusSucceeded = "succeeded"
+	StatusFailed    = "failed"
+)
+
+const taskColumns = `name, schedule, leader_only, paused, run_requested, last_started_at, last_finished_at,
+	COALESCE(last_status, ''), COALESCE(last_error, ''), COALESCE(last_duration_ms, 0), next_run_at`
+
+// Task — сохранённое состояние задачи планировщика.
+type Task struct {
+	Name           string
+	Schedule       string
+	LeaderOnly     bool
+	Paused         bool
+	RunRequested   bool
+	LastStartedAt  *time.Time
+	LastFinishedAt *time.Time
+	LastStatus     string
+	LastError      string
+	LastDuration   time.Duration
+	NextRunAt      *time.Time
+}
+
+func scanTask(row pgx.Row) (*Task, error) {
+	var (
+		t          Task
+		durationMS int64
+	)
+	err := row.Scan(&t.Name, &t.Schedule, &t.LeaderOnly, &t.Paused, &t.RunRequested, &t.LastStartedAt,
+		&t.LastFinishedAt, &t.LastStatus, &t.LastError, &durationMS, &t.NextRunAt)
+	if err != nil {
+		return nil, err
+	}
+	t.LastDuration = time.Duration(durationMS) * time.Millisecond
+	return &t, nil
+}
+
+// List возвращает все известные задачи, включая зарегистрированные другими экземплярами.
+func (s *Scheduler) List(ctx context.Context) ([]*Task, error) {
+	rows, err := s.db.Query(ctx, "SELECT "+taskColumns+" FROM scheduled_tasks ORDER BY name")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tasks: %w", err)
+	}
+	defer rows.Close()
+
+	var tasks []*Task
+	for rows.Next() {
+		t, err := scanTask(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan task: %w", err)
+		}
+		tasks = append(tasks, t)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list tasks: %w", err)
+	}
+
+	return tasks, nil
+}
+
+// RunNow запрашивает внеочередной запуск задачи. Задача будет запущена на ближайшем
+// тике планировщика, даже если она приостановлена.
+func (s *Scheduler) RunNow(ctx context.Context, name string) (*Task, error) {
+	return s.update(ctx, name, "run_requested = true")
+}
+
+// Pause приостанавливает запуск задачи по расписанию на всех экземплярах.
+func (s *Scheduler) Pause(ctx context.Context, name string) (*Task, error) {
+	return s.update(ctx, name, "paused = true")
+}
+
+func (s *Scheduler) Resume(ctx context.Context, name string) (*Task, error) {
+	return s.update(ctx, name, "paused = false")
+}
+
+func (s *Scheduler) update(ctx context.Context, name, set string) (*Task, error) {
+	t, err := scanTask(s.db.QueryRow(ctx,
+		"UPDATE scheduled_tasks SET "+set+", updated_at = now() WHERE name = $1 RETURNING "+taskColumns, name))
+	if err != nil {
+		return nil, fmt.Errorf("task %s: %w", name, postgres.Classify(err))
+	}
+	return t, nil
+}
@@ -0,0 +1,145 @@
+package seeder
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io/fs"
+	"slices"
+	"time"
+
+	"github.com/pressly/goose/v3"
+
+	"github.com/desulaidovich/app/seeds"
+)
+
+const (
+	SetDevelopment = "development"
+	SetTest        = "test"
+
+	envProduction = "production"
+	tablePrefix   = "goose_seed_"
+)
+
+var ErrProduction = errors.New("seeding is not allowed in production")
+
+var sets = []string{SetDevelopment, SetTest}
+
+// Result — итог применения одного сида.
+type Result struct {
+	Version  int64
+	Path     string
+	Duration time.Duration
+}
+
+// Seeder применяет набор сидов из пакета seeds. Применённые сиды запоминаются
+// в отдельной таблице goose, поэтому повторный запуск ничего не дублирует.
+type Seeder struct {
+	db       *sql.DB
+	set      string
+	provider *goose.Provider
+}
+
+type Option func(*Seeder) error
+
+// WithSet выбирает набор сидов вместо набора, совпадающего с окружением.
+func WithSet(set string) Option {
+	return func(s *Seeder) error {
+		if !slices.Contains(sets, set) {
+			return fmt.Errorf("unknown seed set: %q (valid: %s, %s)", set, SetDevelopment, SetTest)
+		}
+		s.set = set
+		return nil
+	}
+}
+
+// New создаёт сидер для окружения env. В production сидер не создаётся.
+func New(dsn, env string, opts ...Option) (_ *Seeder, err error) {
+	if env == envProduction {
+		return nil, ErrProduction
+	}
+
+	s := &Seeder{set: env}
+	for _, opt := range opts {
+		if err := opt(s); err != nil {
+			return nil, fmt.Errorf("seeder option: %w", err)
+		}
+	}
+	if !slices.Contains(sets, s.set) {
+		return nil, fmt.Errorf("no seed set for environment %q, use WithSet", env)
+	}
+
+	fsys, err := fs.Sub(seeds.FS, s.set)
+	if err != nil {
+		return nil, fmt.Errorf("seeder: failed to open seed set %s: %w", s.set, err)
+	}
+
+	s.db, err = sql.Open("pgx", dsn)
+	if err != nil {
+		return nil, fmt.Errorf("seeder: failed to open database: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			_ = s.db.Close()
+		}
+	}()
+
+	s.provider, err = goose.NewProvider(goose.DialectPostgres, s.db, fsys,
+		goose.WithGoMigrations(seeds.GoSeeds(s.set)...),
+		goose.WithDisableGlobalRegistry(true),
+		goose.WithTableName(s.table()),
+		goose.WithAllowOutofOrder(true),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("seeder: failed to create provider: %w", err)
+	}
+
+	return s, nil
+}
+
+func (s *Seeder) Set() string {
+	return s.set
+}
+
+// Seed применяет ещё не применённые сиды набора.
+func (s *Seeder) Seed(ctx context.Context) ([]Result, error) {
+	results, err := s.provider.Up(ctx)
+	if err != nil {
+		var partialErr *goose.PartialError
+		if errors.As(err, &partialErr) {
+			results = partialErr.Applied
+		}
+		return toResults(results), fmt.Errorf("seeder: failed to seed %s: %w", s.set, err)
+	}
+	return toResults(results), nil
+}
+
+// Forget удаляет сведения о применённых сидах, чтобы следующий Seed применил их заново.
+// Используется после сброса схемы.
+func (s *Seeder) Forget(ctx context.Context) error {
+	if _, err := s.db.ExecContext(ctx, "DROP TABLE IF EXISTS "+s.table()); err != nil {
+		return fmt.Errorf("seeder: failed to reset seed history: %w", err)
+	}
+	return nil
+}
+
+func (s *Seeder) Close() error {
+	return s.provider.Close()
+}
+
+func (s *Seeder) table() string {
+	return tablePrefix + s.set
+}
+
+func toResults(results []*goose.MigrationResult) []Result {
+	out := make([]Result, 0, len(results))
+	for _, r := range results {
+		out = append(out, Result{
+			Version:  r.Source.Version,
+			Path:     r.Source.Path,
+			Duration: r.Duration,
+		})
+	}
+	return out
+}
@@ -0,0 +1,52 @@
+package seeder
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	_ "github.com/jackc/pgx/v5/stdlib"
+)
+
+func TestNew(t *testing.T) {
+	const dsn = "postgres://app@localhost:5432/app"
+
+	tests := []struct {
+		name    string
+		env     string
+		opts    []Option
+		wantSet string
+		wantErr error
+		errText string
+	}{
+		{name: "development", env: "development", wantSet: SetDevelopment},
+		{name: "test", env: "test", wantSet: SetTest},
+		{name: "set overrides environment", env: "development", opts: []Option{WithSet(SetTest)}, wantSet: SetTest},
+		{name: "production", env: "production", wantErr: ErrProduction},
+		{name: "production with a set", env: "production", opts: []Option{WithSet(SetDevelopment)}, wantErr: ErrProduction},
+		{name: "unknown environment", env: "staging", errText: `no seed set for environment "staging"`},
+		{name: "unknown set", env: "development", opts: []Option{WithSet("demo")}, errText: `unknown seed set: "demo"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := New(dsn, tt.env, tt.opts...)
+			if tt.wantErr != nil || tt.errText != "" {
+				if err == nil || (tt.wantErr != nil && !errors.Is(err, tt.wantErr)) || !strings.Contains(err.Error(), tt.errText) {
+					t.Fatalf("error = %v, want %v %q", err, tt.wantErr, tt.errText)
+				}
+				if s != nil {
+					t.Errorf("New returned a seeder with an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			defer s.Close()
+			if s.Set() != tt.wantSet {
+				t.Errorf("Set = %q, want %q", s.Set(), tt.wantSet)
+			}
+		})
+	}
+}
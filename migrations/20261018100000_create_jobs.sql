@@ -0,0 +1,29 @@
+-- +goose Up
+CREATE TABLE jobs (
+    id           BIGSERIAL PRIMARY KEY,
+    queue        TEXT        NOT NULL DEFAULT 'default',
+    kind         TEXT        NOT NULL,
+    payload      JSONB       NOT NULL DEFAULT '{}',
+    status       TEXT        NOT NULL DEFAULT 'pending',
+    attempts     INTEGER     NOT NULL DEFAULT 0,
+    max_attempts INTEGER     NOT NULL DEFAULT 25,
+    unique_key   TEXT,
+    last_error   TEXT,
+    run_at       TIMESTAMPTZ NOT NULL DEFAULT now(),
+    locked_at    TIMESTAMPTZ,
+    -- Воркер, который взял задачу: результат записывается, только пока задача принадлежит ему.
+    locked_by    TEXT,
+    finished_at  TIMESTAMPTZ,
+    created_at   TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at   TIMESTAMPTZ NOT NULL DEFAULT now(),
+    CONSTRAINT jobs_status_check CHECK (status IN ('pending', 'running', 'completed', 'dead', 'cancelled')),
+    CONSTRAINT jobs_max_attempts_check CHECK (max_attempts > 0)
+);
+
+CREATE INDEX jobs_fetch_idx ON jobs (queue, run_at, id) WHERE status = 'pending';
+CREATE INDEX jobs_status_idx ON jobs (status, id);
+CREATE UNIQUE INDEX jobs_unique_key_idx ON jobs (kind, unique_key)
+    WHERE unique_key IS NOT NULL AND status IN ('pending', 'running');
+
+-- +goose Down
+DROP TABLE jobs;
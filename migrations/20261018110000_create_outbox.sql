@@ -0,0 +1,17 @@
+-- +goose Up
+CREATE TABLE outbox (
+    id           BIGSERIAL PRIMARY KEY,
+    topic        TEXT        NOT NULL,
+    key          TEXT        NOT NULL DEFAULT '',
+    payload      JSONB       NOT NULL,
+    headers      JSONB       NOT NULL DEFAULT '{}',
+    attempts     INTEGER     NOT NULL DEFAULT 0,
+    last_error   TEXT,
+    created_at   TIMESTAMPTZ NOT NULL DEFAULT now(),
+    delivered_at TIMESTAMPTZ
+);
+
+CREATE INDEX outbox_pending_idx ON outbox (id) WHERE delivered_at IS NULL;
+
+-- +goose Down
+DROP TABLE outbox;
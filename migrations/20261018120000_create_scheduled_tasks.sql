@@ -0,0 +1,19 @@
+-- +goose Up
+CREATE TABLE scheduled_tasks (
+    name             TEXT PRIMARY KEY,
+    schedule         TEXT        NOT NULL,
+    leader_only      BOOLEAN     NOT NULL DEFAULT false,
+    paused           BOOLEAN     NOT NULL DEFAULT false,
+    run_requested    BOOLEAN     NOT NULL DEFAULT false,
+    last_started_at  TIMESTAMPTZ,
+    last_finished_at TIMESTAMPTZ,
+    last_status      TEXT,
+    last_error       TEXT,
+    last_duration_ms BIGINT,
+    next_run_at      TIMESTAMPTZ,
+    updated_at       TIMESTAMPTZ NOT NULL DEFAULT now(),
+    CONSTRAINT scheduled_tasks_last_status_check CHECK (last_status IN ('succeeded', 'failed'))
+);
+
+-- +goose Down
+DROP TABLE scheduled_tasks;
@@ -0,0 +1,31 @@
+package migrations
+
+import (
+	"context"
+	"database/sql"
+	"slices"
+
+	"github.com/pressly/goose/v3"
+)
+
+type migrationFunc func(ctx context.Context, tx *sql.Tx) error
+
+var goMigrations []*goose.Migration
+
+// register добавляет Go-миграцию. Вызывается из init() в файлах миграций этого пакета,
+// поэтому Go-миграции попадают во все бинарники, импортирующие migrations.
+func register(version int64, up, down migrationFunc) {
+	goMigrations = append(goMigrations, goose.NewGoMigration(version, goFunc(up), goFunc(down)))
+}
+
+// GoMigrations возвращает зарегистрированные Go-миграции.
+func GoMigrations() []*goose.Migration {
+	return slices.Clone(goMigrations)
+}
+
+func goFunc(fn migrationFunc) *goose.GoFunc {
+	if fn == nil {
+		return nil
+	}
+	return &goose.GoFunc{RunTx: fn}
+}
@@ -0,0 +1,313 @@
+// Package cli — минимальный каркас консольных утилит с подкомандами поверх пакета flag:
+// вложенные команды, флаги на уровне команды, сгенерированная справка, автодополнение
+// для bash/zsh/fish и коды завершения вместо паник.
+package cli
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+)
+
+// Коды завершения Execute.
+const (
+	ExitOK      = 0
+	ExitFailure = 1
+	ExitUsage   = 2
+)
+
+// Command — узел дерева команд. Команда с подкомандами и без Run только выводит справку.
+type Command struct {
+	// Name — имя команды в командной строке.
+	Name string
+	// Args — описание позиционных аргументов для справки, например "<version>".
+	Args string
+	// Short — однострочное описание для списка команд.
+	Short string
+	// Long — подробное описание, выводится в справке команды.
+	Long string
+	// Run выполняет команду с позиционными аргументами, оставшимися после разбора флагов.
+	Run func(ctx context.Context, args []string) error
+	// Hidden скрывает команду из справки и автодополнения.
+	Hidden bool
+
+	commands []*Command
+	parent   *Command
+	flags    *flag.FlagSet
+}
+
+// Add добавляет подкоманды.
+func (c *Command) Add(cmds ...*Command) *Command {
+	for _, cmd := range cmds {
+		cmd.parent = c
+		c.commands = append(c.commands, cmd)
+	}
+	return c
+}
+
+// Flags возвращает набор флагов команды. Флаги корневой команды глобальные:
+// их можно указывать как до подкоманды, так и после неё.
+func (c *Command) Flags() *flag.FlagSet {
+	if c.flags == nil {
+		c.flags = flag.NewFlagSet(c.Name, flag.ContinueOnError)
+		c.flags.SetOutput(io.Discard)
+	}
+	return c.flags
+}
+
+// parseSet возвращает флаги, которые принимает команда: её собственные и глобальные.
+// Флаг команды перекрывает одноимённый глобальный.
+func (c *Command) parseSet() *flag.FlagSet {
+	root := c.root()
+	if c == root || !hasFlags(root) {
+		return c.Flags()
+	}
+
+	fs := flag.NewFlagSet(c.Name, flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	add := func(f *flag.Flag) {
+		if fs.Lookup(f.Name) == nil {
+			fs.Var(f.Value, f.Name, f.Usage)
+		}
+	}
+	c.Flags().VisitAll(add)
+	root.Flags().VisitAll(add)
+	return fs
+}
+
+// Path возвращает полное имя команды, например "tool migrate up".
+func (c *Command) Path() string {
+	if c.parent == nil {
+		return c.Name
+	}
+	return c.parent.Path() + " " + c.Name
+}
+
+func (c *Command) find(name string) *Command {
+	for _, cmd := range c.commands {
+		if cmd.Name == name {
+			return cmd
+		}
+	}
+	return nil
+}
+
+func (c *Command) root() *Command {
+	if c.parent == nil {
+		return c
+	}
+	return c.parent.root()
+}
+
+// ExitError завершает Execute с заданным кодом.
+type ExitError struct {
+	Code int
+	Err  error
+}
+
+func (e *ExitError) Error() string { return e.Err.Error() }
+func (e *ExitError) Unwrap() error { return e.Err }
+
+// Exit оборачивает err в ExitError с кодом code.
+func Exit(code int, err error) error {
+	return &ExitError{Code: code, Err: err}
+}
+
+type usageError struct{ msg string }
+
+func (e *usageError) Error() string { return e.msg }
+
+// Usagef сообщает о неверном вызове команды: Execute выведет ошибку и справку команды
+// и завершится с ExitUsage.
+func Usagef(format string, args ...any) error {
+	return &usageError{msg: fmt.Sprintf(format, args...)}
+}
+
+// Execute разбирает args, выполняет найденную команду и возвращает код завершения.
+func Execute(ctx context.Context, root *Command, args []string) int {
+	return execute(ctx, root, args, os.Stdout, os.Stderr)
+}
+
+func execute(ctx context.Context, root *Command, args []string, stdout, stderr io.Writer) int {
+	if len(args) > 0 && args[0] == completeCommand {
+		complete(root, args[1:], stdout)
+		return ExitOK
+	}
+
+	cmd := root
+	for {
+		rest, err := parseFlags(cmd, args, len(cmd.commands) == 0)
+		if errors.Is(err, flag.ErrHelp) {
+			printHelp(cmd, stdout)
+			return ExitOK
+		}
+		if err != nil {
+			return usageFailure(cmd, err, stderr)
+		}
+		args = rest
+
+		if len(cmd.commands) == 0 || len(args) == 0 {
+			break
+		}
+		if args[0] == "help" && cmd == root {
+			return help(root, args[1:], stdout, stderr)
+		}
+
+		sub := cmd.find(args[0])
+		if sub == nil {
+			if cmd.Run != nil {
+				break
+			}
+			return usageFailure(cmd, fmt.Errorf("unknown command %q", args[0]), stderr)
+		}
+		cmd, args = sub, args[1:]
+	}
+
+	if cmd.Run == nil {
+		printHelp(cmd, stdout)
+		return ExitOK
+	}
+
+	err := cmd.Run(ctx, args)
+	if err == nil {
+		return ExitOK
+	}
+
+	var usageErr *usageError
+	if errors.As(err, &usageErr) {
+		return usageFailure(cmd, err, stderr)
+	}
+
+	fmt.Fprintf(stderr, "Error: %v\n", err)
+	var exitErr *ExitError
+	if errors.As(err, &exitErr) {
+		return exitErr.Code
+	}
+	return ExitFailure
+}
+
+// parseFlags разбирает флаги команды и глобальные флаги. Для конечных команд флаги могут
+// идти вперемешку с позиционными аргументами, для промежуточных разбор останавливается
+// на подкоманде.
+func parseFlags(cmd *Command, args []string, interspersed bool) ([]string, error) {
+	fs := cmd.parseSet()
+	if !interspersed {
+		if err := fs.Parse(args); err != nil {
+			return nil, err
+		}
+		return fs.Args(), nil
+	}
+
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, err
+		}
+		rest := fs.Args()
+		if len(rest) == 0 {
+			return positional, nil
+		}
+		// "--" завершает флаги: flag удаляет его, всё остальное — позиционные аргументы.
+		if i := len(args) - len(rest); i > 0 && args[i-1] == "--" {
+			return append(positional, rest...), nil
+		}
+		positional = append(positional, rest[0])
+		args = rest[1:]
+	}
+}
+
+func usageFailure(cmd *Command, err error, stderr io.Writer) int {
+	fmt.Fprintf(stderr, "Error: %v\n\n", err)
+	printHelp(cmd, stderr)
+	return ExitUsage
+}
+
+func help(root *Command, path []string, stdout, stderr io.Writer) int {
+	cmd := root
+	for _, name := range path {
+		sub := cmd.find(name)
+		if sub == nil {
+			return usageFailure(cmd, fmt.Errorf("unknown command %q", name), stderr)
+		}
+		cmd = sub
+	}
+	printHelp(cmd, stdout)
+	return ExitOK
+}
+
+func printHelp(cmd *Command, w io.Writer) {
+	if cmd.Long != "" {
+		fmt.Fprintf(w, "%s\n\n", strings.TrimSpace(cmd.Long))
+	} else if cmd.Short != "" {
+		fmt.Fprintf(w, "%s\n\n", cmd.Short)
+	}
+
+	usage := cmd.Path()
+	if cmd.parent != nil && hasFlags(cmd.root()) {
+		usage = cmd.root().Name + " [global flags]" + strings.TrimPrefix(usage, cmd.root().Name)
+	}
+	fmt.Fprintln(w, "Usage:")
+	if len(visible(cmd.commands)) > 0 {
+		fmt.Fprintf(w, "  %s <command>\n", usage)
+	}
+	if cmd.Run != nil || len(cmd.commands) == 0 {
+		line := usage
+		if hasFlags(cmd) {
+			line += " [flags]"
+		}
+		if cmd.Args != "" {
+			line += " " + cmd.Args
+		}
+		fmt.Fprintf(w, "  %s\n", line)
+	}
+
+	if cmds := visible(cmd.commands); len(cmds) > 0 {
+		fmt.Fprintln(w, "\nCommands:")
+		tw := tabwriter.NewWriter(w, 0, 0, 3, ' ', 0)
+		for _, sub := range cmds {
+			fmt.Fprintf(tw, "  %s\t%s\n", sub.Name, sub.Short)
+		}
+		_ = tw.Flush()
+	}
+
+	if hasFlags(cmd) {
+		fmt.Fprintln(w, "\nFlags:")
+		printFlags(cmd.Flags(), w)
+	}
+	if cmd.parent != nil && hasFlags(cmd.root()) {
+		fmt.Fprintln(w, "\nGlobal flags:")
+		printFlags(cmd.root().Flags(), w)
+	}
+
+	if len(visible(cmd.commands)) > 0 {
+		fmt.Fprintf(w, "\nRun '%s help%s <command>' for more information about a command.\n",
+			cmd.root().Name, strings.TrimPrefix(cmd.Path(), cmd.root().Name))
+	}
+}
+
+func printFlags(fs *flag.FlagSet, w io.Writer) {
+	fs.SetOutput(w)
+	fs.PrintDefaults()
+	fs.SetOutput(io.Discard)
+}
+
+func hasFlags(cmd *Command) bool {
+	var n int
+	cmd.Flags().VisitAll(func(*flag.Flag) { n++ })
+	return n > 0
+}
+
+func visible(cmds []*Command) []*Command {
+	out := make([]*Command, 0, len(cmds))
+	for _, cmd := range cmds {
+		if !cmd.Hidden {
+			out = append(out, cmd)
+		}
+	}
+	return out
+}
@@ -0,0 +1,184 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// call — что получила команда run при последнем вызове.
+type call struct {
+	cmd     string
+	args    []string
+	verbose bool
+	dir     string
+	env     string
+}
+
+// newTree собирает дерево "tool [-env-file] migrate {up, create}" и записывает вызовы в got.
+func newTree(got *call, runErr error) *Command {
+	root := &Command{Name: "tool"}
+	envFile := root.Flags().String("env-file", ".env", "dotenv file")
+
+	migrate := &Command{Name: "migrate", Short: "Manage migrations"}
+	verbose := migrate.Flags().Bool("v", false, "verbose")
+
+	up := &Command{Name: "up", Short: "Apply migrations"}
+	up.Run = func(_ context.Context, args []string) error {
+		*got = call{cmd: "up", args: args, verbose: *verbose, env: *envFile}
+		return runErr
+	}
+
+	create := &Command{Name: "create", Args: "<name>", Short: "Create a migration"}
+	dir := create.Flags().String("dir", "migrations", "directory")
+	create.Run = func(_ context.Context, args []string) error {
+		if len(args) != 1 {
+			return Usagef("expected exactly one migration name")
+		}
+		*got = call{cmd: "create", args: args, dir: *dir, env: *envFile}
+		return runErr
+	}
+
+	root.Add(migrate.Add(up, create))
+	return root
+}
+
+func TestExecute(t *testing.T) {
+	tests := []struct {
+		name       string
+		args       []string
+		runErr     error
+		wantCode   int
+		want       call
+		wantStdout string
+		wantStderr string
+	}{
+		{
+			name:     "leaf command",
+			args:     []string{"migrate", "up"},
+			wantCode: ExitOK,
+			want:     call{cmd: "up", env: ".env"},
+		},
+		{
+			name:     "global and intermediate flags",
+			args:     []string{"-env-file", "prod.env", "migrate", "-v", "up"},
+			wantCode: ExitOK,
+			want:     call{cmd: "up", verbose: true, env: "prod.env"},
+		},
+		{
+			name:     "flags interspersed with arguments",
+			args:     []string{"migrate", "create", "add_users", "-dir", "db"},
+			wantCode: ExitOK,
+			want:     call{cmd: "create", args: []string{"add_users"}, dir: "db", env: ".env"},
+		},
+		{
+			name:     "global flag after leaf command",
+			args:     []string{"migrate", "up", "-env-file", "prod.env"},
+			wantCode: ExitOK,
+			want:     call{cmd: "up", env: "prod.env"},
+		},
+		{
+			name:     "global flag after intermediate command",
+			args:     []string{"migrate", "-env-file", "prod.env", "-v", "up"},
+			wantCode: ExitOK,
+			want:     call{cmd: "up", verbose: true, env: "prod.env"},
+		},
+		{
+			name:     "global flag among arguments",
+			args:     []string{"migrate", "create", "add_users", "--env-file=prod.env", "-dir", "db"},
+			wantCode: ExitOK,
+			want:     call{cmd: "create", args: []string{"add_users"}, dir: "db", env: "prod.env"},
+		},
+		{
+			name:     "double dash ends flags",
+			args:     []string{"migrate", "create", "--", "-dir"},
+			wantCode: ExitOK,
+			want:     call{cmd: "create", args: []string{"-dir"}, dir: "migrations", env: ".env"},
+		},
+		{
+			name:       "unknown flag",
+			args:       []string{"migrate", "up", "-force"},
+			wantCode:   ExitUsage,
+			wantStderr: "flag provided but not defined: -force",
+		},
+		{
+			name:       "unknown command",
+			args:       []string{"migrate", "sideways"},
+			wantCode:   ExitUsage,
+			wantStderr: `unknown command "sideways"`,
+		},
+		{
+			name:       "usage error from Run",
+			args:       []string{"migrate", "create"},
+			wantCode:   ExitUsage,
+			wantStderr: "Error: expected exactly one migration name",
+		},
+		{
+			name:       "error from Run",
+			args:       []string{"migrate", "up"},
+			runErr:     errors.New("connection refused"),
+			wantCode:   ExitFailure,
+			want:       call{cmd: "up", env: ".env"},
+			wantStderr: "Error: connection refused",
+		},
+		{
+			name:       "exit code from Run",
+			args:       []string{"migrate", "up"},
+			runErr:     Exit(3, errors.New("drift detected")),
+			wantCode:   3,
+			want:       call{cmd: "up", env: ".env"},
+			wantStderr: "Error: drift detected",
+		},
+		{
+			name:       "group without Run prints help",
+			args:       []string{"migrate"},
+			wantCode:   ExitOK,
+			wantStdout: "Run 'tool help migrate <command>'",
+		},
+		{
+			name:       "help flag",
+			args:       []string{"migrate", "create", "-h"},
+			wantCode:   ExitOK,
+			wantStdout: "tool [global flags] migrate create [flags] <name>",
+		},
+		{
+			name:       "help command",
+			args:       []string{"help", "migrate", "up"},
+			wantCode:   ExitOK,
+			wantStdout: "Apply migrations",
+		},
+		{
+			name:       "help for unknown command",
+			args:       []string{"help", "seed"},
+			wantCode:   ExitUsage,
+			wantStderr: `unknown command "seed"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var (
+				got            call
+				stdout, stderr bytes.Buffer
+			)
+			code := execute(context.Background(), newTree(&got, tt.runErr), tt.args, &stdout, &stderr)
+
+			if code != tt.wantCode {
+				t.Errorf("code = %d, want %d (stderr: %s)", code, tt.wantCode, stderr.String())
+			}
+			if got.cmd != tt.want.cmd || !slices.Equal(got.args, tt.want.args) || got.verbose != tt.want.verbose ||
+				got.dir != tt.want.dir || got.env != tt.want.env {
+				t.Errorf("call = %+v, want %+v", got, tt.want)
+			}
+			if !strings.Contains(stdout.String(), tt.wantStdout) {
+				t.Errorf("stdout %q does not contain %q", stdout.String(), tt.wantStdout)
+			}
+			if !strings.Contains(stderr.String(), tt.wantStderr) {
+				t.Errorf("stderr %q does not contain %q", stderr.String(), tt.wantStderr)
+			}
+		})
+	}
+}
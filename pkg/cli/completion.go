@@ -0,0 +1,113 @@
+package cli
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// completeCommand — скрытая команда, которую вызывают скрипты автодополнения:
+// "tool __complete migrate u" печатает варианты для последнего слова по одному в строке.
+const completeCommand = "__complete"
+
+// complete печатает подкоманды и флаги, подходящие к последнему слову args.
+func complete(root *Command, args []string, w io.Writer) {
+	prefix := ""
+	if len(args) > 0 {
+		prefix, args = args[len(args)-1], args[:len(args)-1]
+	}
+
+	cmd := root
+	for _, arg := range args {
+		if strings.HasPrefix(arg, "-") {
+			continue
+		}
+		if sub := cmd.find(arg); sub != nil {
+			cmd = sub
+		}
+	}
+
+	if strings.HasPrefix(prefix, "-") {
+		cmd.parseSet().VisitAll(func(f *flag.Flag) {
+			if name := "-" + f.Name; strings.HasPrefix(name, prefix) {
+				fmt.Fprintln(w, name)
+			}
+		})
+		return
+	}
+
+	for _, sub := range visible(cmd.commands) {
+		if strings.HasPrefix(sub.Name, prefix) {
+			fmt.Fprintln(w, sub.Name)
+		}
+	}
+	if cmd == root && strings.HasPrefix("help", prefix) {
+		fmt.Fprintln(w, "help")
+	}
+}
+
+// CompletionCommand возвращает команду "completion <bash|zsh|fish>", печатающую скрипт
+// автодополнения для root.
+func CompletionCommand(root *Command) *Command {
+	return &Command{
+		Name:  "completion",
+		Args:  "<bash|zsh|fish>",
+		Short: "Print a shell completion script",
+		Long: fmt.Sprintf(`Print a shell completion script.
+
+  bash: source <(%[1]s completion bash)
+  zsh:  source <(%[1]s completion zsh)
+  fish: %[1]s completion fish | source`, root.Name),
+		Run: func(_ context.Context, args []string) error {
+			if len(args) != 1 {
+				return Usagef("expected exactly one shell")
+			}
+
+			script, ok := completionScripts[args[0]]
+			if !ok {
+				return Usagef("unsupported shell: %q (valid: bash, zsh, fish)", args[0])
+			}
+
+			_, err := fmt.Fprintf(os.Stdout, script, root.Name, completeCommand, identifier(root.Name))
+			return err
+		},
+	}
+}
+
+// identifier превращает имя программы в допустимое имя функции оболочки.
+func identifier(name string) string {
+	return strings.Map(func(r rune) rune {
+		if r == '-' || r == '.' {
+			return '_'
+		}
+		return r
+	}, name)
+}
+
+// Скрипты получают имя программы (%[1]s), имя скрытой команды (%[2]s) и имя функции (%[3]s).
+var completionScripts = map[string]string{
+	"bash": `_%[3]s_complete() {
+    local IFS=$'\n'
+    COMPREPLY=($(%[1]s %[2]s "${COMP_WORDS[@]:1:COMP_CWORD}" 2>/dev/null))
+}
+complete -o default -F _%[3]s_complete %[1]s
+`,
+	"zsh": `#compdef %[1]s
+
+_%[3]s() {
+    local -a candidates
+    candidates=("${(@f)$(%[1]s %[2]s "${(@)words[2,CURRENT]}" 2>/dev/null)}")
+    compadd -- $candidates
+}
+compdef _%[3]s %[1]s
+`,
+	"fish": `function __%[3]s_complete
+    set -l tokens (commandline -opc)
+    %[1]s %[2]s $tokens[2..-1] (commandline -ct) 2>/dev/null
+end
+complete -c %[1]s -f -a '(__%[3]s_complete)'
+`,
+}
@@ -0,0 +1,67 @@
+package env
+
+import (
+	"encoding"
+	"fmt"
+	"net/url"
+	"reflect"
+	"sync"
+)
+
+var (
+	decodersMu sync.RWMutex
+	// decoders по умолчанию покрывает типы без TextUnmarshaler, которые часто встречаются в конфигурации.
+	decoders = map[reflect.Type]func(string) (any, error){
+		reflect.TypeFor[url.URL](): func(value string) (any, error) {
+			u, err := url.Parse(value)
+			if err != nil {
+				return nil, err
+			}
+			return *u, nil
+		},
+	}
+)
+
+var textUnmarshalerType = reflect.TypeFor[encoding.TextUnmarshaler]()
+
+// RegisterDecoder задаёт разбор значений типа T, в том числе элементов срезов и map.
+// Декодер имеет приоритет над encoding.TextUnmarshaler и встроенным разбором.
+// Повторная регистрация заменяет прежний декодер.
+func RegisterDecoder[T any](decode func(value string) (T, error)) {
+	decodersMu.Lock()
+	defer decodersMu.Unlock()
+	decoders[reflect.TypeFor[T]()] = func(value string) (any, error) {
+		return decode(value)
+	}
+}
+
+func decoderFor(typ reflect.Type) (func(string) (any, error), bool) {
+	decodersMu.RLock()
+	defer decodersMu.RUnlock()
+	decode, ok := decoders[typ]
+	return decode, ok
+}
+
+// decodable сообщает, что тип разбирается из одной строки целиком: через декодер или
+// TextUnmarshaler. Такие структуры, например time.Time или url.URL, не раскрываются во вложенные поля.
+func decodable(typ reflect.Type) bool {
+	if _, ok := decoderFor(typ); ok {
+		return true
+	}
+	return reflect.PointerTo(typ).Implements(textUnmarshalerType)
+}
+
+// text возвращает строковое представление значения для проверок и сообщений об ошибках.
+func text(v reflect.Value) string {
+	if v.CanAddr() {
+		switch t := v.Addr().Interface().(type) {
+		case encoding.TextMarshaler:
+			if b, err := t.MarshalText(); err == nil {
+				return string(b)
+			}
+		case fmt.Stringer:
+			return t.String()
+		}
+	}
+	return fmt.Sprint(v.Interface())
+}
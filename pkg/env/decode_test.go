@@ -0,0 +1,186 @@
+package env
+
+import (
+	"errors"
+	"net"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func unregisterDecoder[T any]() {
+	decodersMu.Lock()
+	defer decodersMu.Unlock()
+	delete(decoders, reflect.TypeFor[T]())
+}
+
+// level разбирается и через UnmarshalText, и через декодер, зарегистрированный в тесте.
+type level int
+
+func (l *level) UnmarshalText(text []byte) error {
+	switch string(text) {
+	case "low":
+		*l = 1
+	case "high":
+		*l = 2
+	default:
+		return errors.New("unknown level")
+	}
+	return nil
+}
+
+type upper string
+
+func TestDecoders(t *testing.T) {
+	RegisterDecoder(func(value string) (upper, error) {
+		if value == "" {
+			return "", errors.New("empty")
+		}
+		return upper(strings.ToUpper(value)), nil
+	})
+	t.Cleanup(func() { unregisterDecoder[upper]() })
+
+	type config struct {
+		Started  time.Time         `env:"STARTED"`
+		IP       net.IP            `env:"IP"`
+		Endpoint url.URL           `env:"ENDPOINT"`
+		Proxy    *url.URL          `env:"PROXY"`
+		Level    level             `env:"LEVEL"`
+		Levels   []level           `env:"LEVELS"`
+		Periods  []time.Duration   `env:"PERIODS,sep=;"`
+		Weights  map[string]int    `env:"WEIGHTS"`
+		Limits   map[string]string `env:"LIMITS,sep=;,kvsep=="`
+		Name     upper             `env:"NAME"`
+	}
+
+	tests := []struct {
+		name    string
+		lines   []string
+		check   func(t *testing.T, cfg config)
+		wantErr string
+	}{
+		{
+			name:  "TextUnmarshaler",
+			lines: []string{"STARTED=2026-10-18T10:00:00Z", "IP=10.0.0.1", "LEVEL=high"},
+			check: func(t *testing.T, cfg config) {
+				if want := time.Date(2026, 10, 18, 10, 0, 0, 0, time.UTC); !cfg.Started.Equal(want) {
+					t.Errorf("Started = %s, want %s", cfg.Started, want)
+				}
+				if !cfg.IP.Equal(net.IPv4(10, 0, 0, 1)) {
+					t.Errorf("IP = %s", cfg.IP)
+				}
+				if cfg.Level != 2 {
+					t.Errorf("Level = %d, want 2", cfg.Level)
+				}
+			},
+		},
+		{
+			name:  "built-in url decoder",
+			lines: []string{"ENDPOINT=https://example.com:8443/api", "PROXY=http://proxy:3128"},
+			check: func(t *testing.T, cfg config) {
+				if cfg.Endpoint.Host != "example.com:8443" || cfg.Endpoint.Path != "/api" {
+					t.Errorf("Endpoint = %s", cfg.Endpoint.String())
+				}
+				if cfg.Proxy == nil || cfg.Proxy.Host != "proxy:3128" {
+					t.Errorf("Proxy = %v", cfg.Proxy)
+				}
+			},
+		},
+		{
+			name:  "slices of decodable elements",
+			lines: []string{"LEVELS=low, high", "PERIODS=1s;2m"},
+			check: func(t *testing.T, cfg config) {
+				if want := []level{1, 2}; !reflect.DeepEqual(cfg.Levels, want) {
+					t.Errorf("Levels = %v, want %v", cfg.Levels, want)
+				}
+				if want := []time.Duration{time.Second, 2 * time.Minute}; !reflect.DeepEqual(cfg.Periods, want) {
+					t.Errorf("Periods = %v, want %v", cfg.Periods, want)
+				}
+			},
+		},
+		{
+			name:  "maps",
+			lines: []string{"WEIGHTS=a:1, b:2,", "LIMITS=cpu=2;mem=1Gi"},
+			check: func(t *testing.T, cfg config) {
+				if want := map[string]int{"a": 1, "b": 2}; !reflect.DeepEqual(cfg.Weights, want) {
+					t.Errorf("Weights = %v, want %v", cfg.Weights, want)
+				}
+				if want := map[string]string{"cpu": "2", "mem": "1Gi"}; !reflect.DeepEqual(cfg.Limits, want) {
+					t.Errorf("Limits = %v, want %v", cfg.Limits, want)
+				}
+			},
+		},
+		{
+			name:  "registered decoder",
+			lines: []string{"NAME=app"},
+			check: func(t *testing.T, cfg config) {
+				if cfg.Name != "APP" {
+					t.Errorf("Name = %q, want APP", cfg.Name)
+				}
+			},
+		},
+		{
+			name:    "TextUnmarshaler error",
+			lines:   []string{"LEVEL=medium"},
+			wantErr: "field LEVEL: invalid env.level: unknown level",
+		},
+		{
+			name:    "slice element error",
+			lines:   []string{"LEVELS=low,medium"},
+			wantErr: "field LEVELS: item 1: invalid env.level",
+		},
+		{
+			name:    "map item without separator",
+			lines:   []string{"WEIGHTS=a"},
+			wantErr: `field WEIGHTS: invalid map item "a", expected key:value`,
+		},
+		{
+			name:    "map value error",
+			lines:   []string{"WEIGHTS=a:x"},
+			wantErr: `field WEIGHTS: value for "a": invalid int`,
+		},
+		{
+			name:    "invalid url",
+			lines:   []string{"ENDPOINT=http://[::1"},
+			wantErr: "field ENDPOINT: invalid url.URL",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var cfg config
+			err := LoadFile(&cfg, dotenv(t, tt.lines...))
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			tt.check(t, cfg)
+		})
+	}
+}
+
+func TestRegisterDecoderOverridesTextUnmarshaler(t *testing.T) {
+	type config struct {
+		Level level `env:"LEVEL"`
+	}
+
+	RegisterDecoder(func(value string) (level, error) {
+		return level(len(value)), nil
+	})
+	t.Cleanup(func() { unregisterDecoder[level]() })
+
+	var cfg config
+	if err := LoadFile(&cfg, dotenv(t, "LEVEL=medium")); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.Level != 6 {
+		t.Errorf("Level = %d, want 6 from the registered decoder", cfg.Level)
+	}
+}
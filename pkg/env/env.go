@@ -1,22 +1,36 @@
 package env
 
 import (
+	"encoding"
 	"errors"
 	"fmt"
+	"maps"
 	"os"
 	"reflect"
+	"regexp"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
 )
 
+// fileSuffix — суффикс переменной с путём к файлу значения: DATABASE_USER_PASSWORD_FILE
+// читает DATABASE_USER_PASSWORD из файла, например, из смонтированного секрета.
+const fileSuffix = "_FILE"
+
 type field struct {
-	index      []int
-	envKey     string
-	required   bool
-	defValue   string
-	sep        string
-	isDuration bool
+	index    []int
+	envKey   string
+	prefix   string
+	typ      reflect.Type
+	required bool
+	secret   bool
+	file     bool
+	defValue string
+	desc     string
+	sep      string
+	kvSep    string
+	rules    rules
 }
 
 // Load загружает конфигурацию из .env файлов и переменных окружения системы.
@@ -50,25 +64,47 @@ func buildEnvMap(files []string, includeSystem bool) (map[string]string, error)
 	envMap := make(map[string]string)
 
 	for _, file := range files {
-		if err := loadFile(envMap, file); err != nil {
+		fileValues := make(map[string]string)
+		if err := loadFile(fileValues, file); err != nil {
 			if !os.IsNotExist(err) {
 				return nil, fmt.Errorf("load file %s: %w", file, err)
 			}
 		}
+		overlay(envMap, fileValues)
 	}
 
 	if includeSystem {
-		for _, kv := range os.Environ() {
-			parts := strings.SplitN(kv, "=", 2)
-			if len(parts) == 2 {
-				envMap[parts[0]] = parts[1]
-			}
-		}
+		overlay(envMap, systemEnv())
 	}
 
 	return envMap, nil
 }
 
+func systemEnv() map[string]string {
+	values := make(map[string]string)
+	for _, kv := range os.Environ() {
+		if key, value, ok := strings.Cut(kv, "="); ok {
+			values[key] = value
+		}
+	}
+	return values
+}
+
+// overlay переносит значения layer поверх dst. KEY и KEY_FILE заменяются парой: KEY_FILE
+// из окружения перекрывает KEY из .env файла, а не конфликтует с ним.
+func overlay(dst, layer map[string]string) {
+	for key := range layer {
+		pair, ok := strings.CutSuffix(key, fileSuffix)
+		if !ok {
+			pair = key + fileSuffix
+		}
+		if _, ok := layer[pair]; !ok {
+			delete(dst, pair)
+		}
+	}
+	maps.Copy(dst, layer)
+}
+
 func loadFile(dest map[string]string, filename string) error {
 	data, err := os.ReadFile(filename)
 	if err != nil {
@@ -119,26 +155,67 @@ func apply(cfg any, envMap map[string]string) error {
 	for _, info := range infos {
 		field := val.FieldByIndex(info.index)
 
-		rawValue, ok := envMap[info.envKey]
-		if !ok || rawValue == "" {
-			if info.defValue != "" {
-				rawValue = info.defValue
-			} else if info.required {
+		rawValue, _, _, err := info.resolve(envMap)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("field %s: %w", info.envKey, err))
+			continue
+		}
+		if rawValue == "" {
+			if info.required {
 				errs = append(errs, fmt.Errorf("missing required env: %s", info.envKey))
-				continue
-			} else {
-				continue
 			}
+			continue
 		}
 
 		if err := setFieldValue(field, rawValue, info); err != nil {
+			if info.secret {
+				err = secretError(info.typ, err)
+			}
+			errs = append(errs, fmt.Errorf("field %s: %w", info.envKey, err))
+			continue
+		}
+		for _, err := range info.rules.check(field, info.secret) {
 			errs = append(errs, fmt.Errorf("field %s: %w", info.envKey, err))
 		}
 	}
 
+	errs = append(errs, validateStructs(val)...)
 	return errors.Join(errs...)
 }
 
+// resolve возвращает сырое значение поля и ключ, из которого оно взято: KEY, KEY_FILE или
+// пустой ключ для значения по умолчанию. Значение KEY_FILE, а с опцией file — значение KEY
+// и значение по умолчанию, считается путём к файлу: возвращается его содержимое без пробелов
+// по краям и путь.
+func (f field) resolve(envMap map[string]string) (value, key, path string, err error) {
+	fileKey := f.envKey + fileSuffix
+	value, fileValue := envMap[f.envKey], envMap[fileKey]
+	switch {
+	case value != "" && fileValue != "":
+		return value, f.envKey, "", fmt.Errorf("both %s and %s are set", f.envKey, fileKey)
+	case value != "":
+		key = f.envKey
+	case fileValue != "":
+		key, path = fileKey, fileValue
+	case f.defValue != "":
+		value = f.defValue
+	default:
+		return "", "", "", nil
+	}
+	if f.file && path == "" {
+		path = value
+	}
+	if path == "" {
+		return value, key, "", nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", key, path, fmt.Errorf("read file: %w", err)
+	}
+	return strings.TrimSpace(string(data)), key, path, nil
+}
+
 func buildFields(typ reflect.Type, path []int, prefix string) ([]field, error) {
 	if typ.Kind() == reflect.Pointer {
 		typ = typ.Elem()
@@ -160,12 +237,12 @@ func buildFields(typ reflect.Type, path []int, prefix string) ([]field, error) {
 			continue
 		}
 
-		key, required, defValue, sep := parseEnvTag(tag)
+		key, opts := parseEnvTag(tag)
 
 		currentPath := append(path, i)
 		envKey := buildEnvKey(prefix, f.Name, key)
 
-		if f.Type.Kind() == reflect.Struct && f.Type != reflect.TypeFor[time.Time]() {
+		if f.Type.Kind() == reflect.Struct && !decodable(f.Type) {
 			nested, err := buildFields(f.Type, currentPath, envKey+"_")
 			if err != nil {
 				return nil, err
@@ -173,7 +250,7 @@ func buildFields(typ reflect.Type, path []int, prefix string) ([]field, error) {
 			infos = append(infos, nested...)
 			continue
 		}
-		if f.Type.Kind() == reflect.Ptr && f.Type.Elem().Kind() == reflect.Struct && f.Type.Elem() != reflect.TypeFor[time.Time]() {
+		if f.Type.Kind() == reflect.Ptr && f.Type.Elem().Kind() == reflect.Struct && !decodable(f.Type.Elem()) {
 			nested, err := buildFields(f.Type.Elem(), currentPath, envKey+"_")
 			if err != nil {
 				return nil, err
@@ -182,39 +259,88 @@ func buildFields(typ reflect.Type, path []int, prefix string) ([]field, error) {
 			continue
 		}
 
-		if sep == "" {
-			sep = ","
+		if opts.sep == "" {
+			opts.sep = ","
+		}
+		if opts.kvSep == "" {
+			opts.kvSep = ":"
+		}
+
+		fieldRules := opts.rules
+		if opts.regex != "" {
+			re, err := regexp.Compile(opts.regex)
+			if err != nil {
+				return nil, fmt.Errorf("field %s: invalid regex: %w", envKey, err)
+			}
+			fieldRules.regex = re
 		}
 
 		infos = append(infos, field{
-			index:      currentPath,
-			envKey:     envKey,
-			required:   required,
-			defValue:   defValue,
-			sep:        sep,
-			isDuration: f.Type == reflect.TypeFor[time.Duration](),
+			index:    slices.Clone(currentPath),
+			envKey:   envKey,
+			prefix:   prefix,
+			typ:      f.Type,
+			required: opts.required,
+			secret:   opts.secret || f.Type == reflect.TypeFor[Secret](),
+			file:     opts.file,
+			defValue: opts.defValue,
+			desc:     f.Tag.Get("desc"),
+			sep:      opts.sep,
+			kvSep:    opts.kvSep,
+			rules:    fieldRules,
 		})
 	}
 
 	return infos, nil
 }
 
-func parseEnvTag(tag string) (key string, required bool, defValue string, sep string) {
+type tagOptions struct {
+	required bool
+	secret   bool
+	file     bool
+	defValue string
+	sep      string
+	kvSep    string
+	rules    rules
+	regex    string
+}
+
+func parseEnvTag(tag string) (key string, opts tagOptions) {
 	parts := strings.Split(tag, ",")
 
 	if parts[0] != "" {
 		key = parts[0]
 	}
 
-	for _, part := range parts[1:] {
+	for i, part := range parts[1:] {
 		part = strings.TrimSpace(part)
 		switch {
+		case strings.HasPrefix(part, "regex="):
+			// Выражение может содержать запятые, поэтому забирает остаток тега.
+			opts.regex = strings.TrimPrefix(strings.Join(parts[i+1:], ","), "regex=")
+			return
 		case part == "required":
-			required = true
+			opts.required = true
+		case part == "secret":
+			opts.secret = true
+		case part == "file":
+			opts.file = true
 		case strings.HasPrefix(part, "default="):
-			defValue = strings.Trim(strings.TrimPrefix(part, "default="), "\"'")
+			opts.defValue = strings.Trim(strings.TrimPrefix(part, "default="), "\"'")
 		case strings.HasPrefix(part, "sep="):
-			sep = strings.Trim(strings.TrimPrefix(part, "sep="), "\"'")
+			opts.sep = strings.Trim(strings.TrimPrefix(part, "sep="), "\"'")
+		case strings.HasPrefix(part, "kvsep="):
+			opts.kvSep = strings.Trim(strings.TrimPrefix(part, "kvsep="), "\"'")
+		case strings.HasPrefix(part, "min="):
+			opts.rules.min = strings.TrimPrefix(part, "min=")
+		case strings.HasPrefix(part, "max="):
+			opts.rules.max = strings.TrimPrefix(part, "max=")
+		case strings.HasPrefix(part, "oneof="):
+			opts.rules.oneof = strings.Fields(strings.TrimPrefix(part, "oneof="))
+		case part == "url":
+			opts.rules.url = true
+		case part == "hostport":
+			opts.rules.hostport = true
 		}
 	}
 
@@ -228,6 +354,16 @@ func buildEnvKey(prefix, fieldName, key string) string {
 	return strings.ToUpper(prefix + fieldName)
 }
 
+// secretError заменяет ошибку разбора секретного поля: ошибки strconv, time и декодеров
+// содержат исходную строку.
+func secretError(typ reflect.Type, err error) error {
+	var numErr *strconv.NumError
+	if errors.As(err, &numErr) {
+		return fmt.Errorf("invalid %s %s: %w", typ, Redacted, numErr.Err)
+	}
+	return fmt.Errorf("invalid %s %s", typ, Redacted)
+}
+
 func setFieldValue(field reflect.Value, rawValue string, info field) error {
 	if !field.CanSet() {
 		return nil
@@ -240,52 +376,79 @@ func setFieldValue(field reflect.Value, rawValue string, info field) error {
 		field = field.Elem()
 	}
 
-	if info.isDuration {
+	if !decodable(field.Type()) {
+		switch field.Kind() {
+		case reflect.Slice:
+			return setSliceValue(field, rawValue, info.sep)
+		case reflect.Map:
+			return setMapValue(field, rawValue, info.sep, info.kvSep)
+		}
+	}
+
+	return setValue(field, rawValue)
+}
+
+// setValue разбирает одно значение: поле, элемент среза, ключ или значение map.
+func setValue(v reflect.Value, rawValue string) error {
+	if decode, ok := decoderFor(v.Type()); ok {
+		decoded, err := decode(rawValue)
+		if err != nil {
+			return fmt.Errorf("invalid %s: %w", v.Type(), err)
+		}
+		v.Set(reflect.ValueOf(decoded))
+		return nil
+	}
+
+	if u, ok := v.Addr().Interface().(encoding.TextUnmarshaler); ok {
+		if err := u.UnmarshalText([]byte(rawValue)); err != nil {
+			return fmt.Errorf("invalid %s: %w", v.Type(), err)
+		}
+		return nil
+	}
+
+	if v.Type() == reflect.TypeFor[time.Duration]() {
 		d, err := time.ParseDuration(rawValue)
 		if err != nil {
 			return fmt.Errorf("invalid duration: %w", err)
 		}
-		field.Set(reflect.ValueOf(d))
+		v.SetInt(int64(d))
 		return nil
 	}
 
-	switch field.Kind() {
+	switch v.Kind() {
 	case reflect.String:
-		field.SetString(rawValue)
+		v.SetString(rawValue)
 
 	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
-		v, err := strconv.ParseInt(rawValue, 10, 64)
+		n, err := strconv.ParseInt(rawValue, 10, v.Type().Bits())
 		if err != nil {
 			return fmt.Errorf("invalid int: %w", err)
 		}
-		field.SetInt(v)
+		v.SetInt(n)
 
 	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
-		v, err := strconv.ParseUint(rawValue, 10, 64)
+		n, err := strconv.ParseUint(rawValue, 10, v.Type().Bits())
 		if err != nil {
 			return fmt.Errorf("invalid uint: %w", err)
 		}
-		field.SetUint(v)
+		v.SetUint(n)
 
 	case reflect.Float32, reflect.Float64:
-		v, err := strconv.ParseFloat(rawValue, 64)
+		n, err := strconv.ParseFloat(rawValue, v.Type().Bits())
 		if err != nil {
 			return fmt.Errorf("invalid float: %w", err)
 		}
-		field.SetFloat(v)
+		v.SetFloat(n)
 
 	case reflect.Bool:
-		v, err := strconv.ParseBool(rawValue)
+		b, err := strconv.ParseBool(rawValue)
 		if err != nil {
 			return fmt.Errorf("invalid bool: %w", err)
 		}
-		field.SetBool(v)
-
-	case reflect.Slice:
-		return setSliceValue(field, rawValue, info.sep)
+		v.SetBool(b)
 
 	default:
-		return fmt.Errorf("unsupported type %s", field.Kind())
+		return fmt.Errorf("unsupported type %s", v.Type())
 	}
 
 	return nil
@@ -296,46 +459,42 @@ func setSliceValue(field reflect.Value, rawValue string, sep string) error {
 	slice := reflect.MakeSlice(field.Type(), len(items), len(items))
 
 	for i, item := range items {
-		item = strings.TrimSpace(item)
-		elem := slice.Index(i)
-
-		switch elem.Kind() {
-		case reflect.String:
-			elem.SetString(item)
+		if err := setValue(slice.Index(i), strings.TrimSpace(item)); err != nil {
+			return fmt.Errorf("item %d: %w", i, err)
+		}
+	}
 
-		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
-			v, err := strconv.ParseInt(item, 10, 64)
-			if err != nil {
-				return fmt.Errorf("invalid int in slice: %w", err)
-			}
-			elem.SetInt(v)
+	field.Set(slice)
+	return nil
+}
 
-		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
-			v, err := strconv.ParseUint(item, 10, 64)
-			if err != nil {
-				return fmt.Errorf("invalid uint in slice: %w", err)
-			}
-			elem.SetUint(v)
+// setMapValue разбирает значение вида a:1,b:2. Пустые элементы пропускаются.
+func setMapValue(field reflect.Value, rawValue string, sep, kvSep string) error {
+	typ := field.Type()
+	m := reflect.MakeMap(typ)
 
-		case reflect.Float32, reflect.Float64:
-			v, err := strconv.ParseFloat(item, 64)
-			if err != nil {
-				return fmt.Errorf("invalid float in slice: %w", err)
-			}
-			elem.SetFloat(v)
+	for item := range strings.SplitSeq(rawValue, sep) {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
 
-		case reflect.Bool:
-			v, err := strconv.ParseBool(item)
-			if err != nil {
-				return fmt.Errorf("invalid bool in slice: %w", err)
-			}
-			elem.SetBool(v)
+		rawKey, rawVal, ok := strings.Cut(item, kvSep)
+		if !ok {
+			return fmt.Errorf("invalid map item %q, expected key%svalue", item, kvSep)
+		}
 
-		default:
-			return fmt.Errorf("unsupported slice element type %s", elem.Kind())
+		key := reflect.New(typ.Key()).Elem()
+		if err := setValue(key, strings.TrimSpace(rawKey)); err != nil {
+			return fmt.Errorf("key %q: %w", rawKey, err)
 		}
+		val := reflect.New(typ.Elem()).Elem()
+		if err := setValue(val, strings.TrimSpace(rawVal)); err != nil {
+			return fmt.Errorf("value for %q: %w", rawKey, err)
+		}
+		m.SetMapIndex(key, val)
 	}
 
-	field.Set(slice)
+	field.Set(m)
 	return nil
 }
@@ -0,0 +1,218 @@
+package env
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"slices"
+	"strings"
+)
+
+// Source — откуда взято значение переменной.
+type Source string
+
+const (
+	SourceDefault Source = "default"
+	SourceFile    Source = "file"
+	SourceEnv     Source = "env"
+	SourceUnset   Source = "unset"
+)
+
+// Redacted заменяет значения секретных переменных при выводе.
+const Redacted = "[REDACTED]"
+
+// Var описывает переменную конфигурации и её итоговое значение.
+type Var struct {
+	Key         string `json:"key"`
+	Type        string `json:"type"`
+	Default     string `json:"default,omitempty"`
+	Required    bool   `json:"required,omitempty"`
+	Secret      bool   `json:"secret,omitempty"`
+	Description string `json:"description,omitempty"`
+	Value       string `json:"value"`
+	Source      Source `json:"source"`
+	// File — файл, из которого прочитано значение: через KEY_FILE или опцию file.
+	File string `json:"file,omitempty"`
+}
+
+// Display возвращает значение для вывода: непустые секреты скрываются.
+func (v Var) Display() string {
+	if v.Secret && v.Value != "" {
+		return Redacted
+	}
+	return v.Value
+}
+
+// UnknownVar — переменная из пространства имён конфигурации (например, DATABASE_),
+// которой нет в структуре. Обычно это опечатка.
+type UnknownVar struct {
+	Key        string `json:"key"`
+	Source     Source `json:"source"`
+	Suggestion string `json:"suggestion,omitempty"`
+}
+
+// Describe возвращает переменные структуры cfg без загрузки значений.
+func Describe(cfg any) ([]Var, error) {
+	infos, err := fieldsOf(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	vars := make([]Var, 0, len(infos))
+	for _, info := range infos {
+		vars = append(vars, info.describe())
+	}
+	return vars, nil
+}
+
+// Inspect возвращает переменные структуры cfg с итоговыми значениями и их источниками
+// так, как их увидел бы Load с теми же файлами. Файлы из KEY_FILE читаются, но значения
+// не разбираются: ошибки разбора возвращает Load.
+func Inspect(cfg any, files ...string) ([]Var, error) {
+	infos, err := fieldsOf(cfg)
+	if err != nil {
+		return nil, err
+	}
+	values, sources, err := buildSources(files)
+	if err != nil {
+		return nil, err
+	}
+
+	vars := make([]Var, 0, len(infos))
+	for _, info := range infos {
+		v := info.describe()
+		// Ошибки чтения файлов возвращает Load.
+		value, key, path, _ := info.resolve(values)
+		v.Value, v.File = value, path
+		switch {
+		case key != "":
+			v.Source = sources[key]
+		case value != "" || path != "":
+			v.Source = SourceDefault
+		default:
+			v.Source = SourceUnset
+		}
+		vars = append(vars, v)
+	}
+	return vars, nil
+}
+
+// Unknown возвращает переменные, которые начинаются с префикса одной из вложенных
+// структур cfg, но не соответствуют ни одному полю. Окружение процесса общее с другими
+// программами (например, GRPC_TRACE у grpc-go), поэтому из него попадают только ключи,
+// похожие на известные.
+func Unknown(cfg any, files ...string) ([]UnknownVar, error) {
+	infos, err := fieldsOf(cfg)
+	if err != nil {
+		return nil, err
+	}
+	_, sources, err := buildSources(files)
+	if err != nil {
+		return nil, err
+	}
+
+	known := make([]string, 0, len(infos))
+	var prefixes []string
+	for _, info := range infos {
+		known = append(known, info.envKey)
+		if info.prefix != "" && !slices.Contains(prefixes, info.prefix) {
+			prefixes = append(prefixes, info.prefix)
+		}
+	}
+
+	var unknown []UnknownVar
+	for key, source := range sources {
+		if slices.Contains(known, key) || slices.Contains(known, strings.TrimSuffix(key, fileSuffix)) {
+			continue
+		}
+		if !slices.ContainsFunc(prefixes, func(p string) bool { return strings.HasPrefix(key, p) }) {
+			continue
+		}
+		suggestion := closest(key, known)
+		if source == SourceEnv && suggestion == "" {
+			continue
+		}
+		unknown = append(unknown, UnknownVar{Key: key, Source: source, Suggestion: suggestion})
+	}
+	slices.SortFunc(unknown, func(a, b UnknownVar) int { return strings.Compare(a.Key, b.Key) })
+	return unknown, nil
+}
+
+func fieldsOf(cfg any) ([]field, error) {
+	typ := reflect.TypeOf(cfg)
+	if typ == nil {
+		return nil, fmt.Errorf("cfg must be a struct or a pointer to struct")
+	}
+	return buildFields(typ, nil, "")
+}
+
+func (f field) describe() Var {
+	return Var{
+		Key:         f.envKey,
+		Type:        f.typ.String(),
+		Default:     f.defValue,
+		Required:    f.required,
+		Secret:      f.secret,
+		Description: f.desc,
+	}
+}
+
+// buildSources собирает значения так же, как buildEnvMap, и запоминает источник каждого.
+func buildSources(files []string) (map[string]string, map[string]Source, error) {
+	values := make(map[string]string)
+	sources := make(map[string]Source)
+
+	for _, file := range files {
+		fileValues := make(map[string]string)
+		if err := loadFile(fileValues, file); err != nil {
+			if !os.IsNotExist(err) {
+				return nil, nil, fmt.Errorf("load file %s: %w", file, err)
+			}
+		}
+		overlay(values, fileValues)
+		for key := range fileValues {
+			sources[key] = SourceFile
+		}
+	}
+
+	system := systemEnv()
+	overlay(values, system)
+	for key := range system {
+		sources[key] = SourceEnv
+	}
+
+	return values, sources, nil
+}
+
+// closest возвращает известный ключ, ближайший к key, если он отличается не больше
+// чем на три символа.
+func closest(key string, known []string) string {
+	best, bestDist := "", 4
+	for _, k := range known {
+		if d := distance(key, k); d < bestDist {
+			best, bestDist = k, d
+		}
+	}
+	return best
+}
+
+// distance — расстояние Левенштейна.
+func distance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(b)]
+}
@@ -0,0 +1,95 @@
+package env
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestUnknown(t *testing.T) {
+	type config struct {
+		Database struct {
+			Host     string `env:"HOST"`
+			Port     int    `env:"PORT"`
+			Password Secret `env:"PASSWORD"`
+		} `env:"DATABASE"`
+		GRPC struct {
+			Port string `env:"PORT"`
+		} `env:"GRPC"`
+	}
+
+	tests := []struct {
+		name  string
+		lines []string
+		env   map[string]string
+		want  []UnknownVar
+	}{
+		{
+			name:  "known keys and _FILE variants",
+			lines: []string{"DATABASE_HOST=db", "DATABASE_PASSWORD_FILE=/run/secrets/db", "OTHER_KEY=1"},
+		},
+		{
+			name:  "typo in file",
+			lines: []string{"DATABASE_HSOT=db"},
+			want:  []UnknownVar{{Key: "DATABASE_HSOT", Source: SourceFile, Suggestion: "DATABASE_HOST"}},
+		},
+		{
+			name:  "unrelated key in file is reported without suggestion",
+			lines: []string{"DATABASE_REPLICATION_SLOT=main"},
+			want:  []UnknownVar{{Key: "DATABASE_REPLICATION_SLOT", Source: SourceFile}},
+		},
+		{
+			name: "typo in environment",
+			env:  map[string]string{"DATABASE_PROT": "5432"},
+			want: []UnknownVar{{Key: "DATABASE_PROT", Source: SourceEnv, Suggestion: "DATABASE_PORT"}},
+		},
+		{
+			name: "foreign variable in environment is ignored",
+			env:  map[string]string{"GRPC_GO_LOG_SEVERITY_LEVEL": "info"},
+		},
+		{
+			name:  "environment overrides file source",
+			lines: []string{"GRPC_PROT=9090"},
+			env:   map[string]string{"GRPC_PROT": "9091"},
+			want:  []UnknownVar{{Key: "GRPC_PROT", Source: SourceEnv, Suggestion: "GRPC_PORT"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for key, value := range tt.env {
+				t.Setenv(key, value)
+			}
+
+			got, err := Unknown(config{}, dotenv(t, tt.lines...))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Unknown = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestClosest(t *testing.T) {
+	known := []string{"APP_NAME", "APP_ENV", "HTTP_PORT"}
+
+	tests := []struct {
+		key  string
+		want string
+	}{
+		{key: "APP_NAME", want: "APP_NAME"},
+		{key: "APP_NMAE", want: "APP_NAME"},
+		{key: "APP_EVN", want: "APP_ENV"},
+		{key: "HTTP_PORTS", want: "HTTP_PORT"},
+		{key: "HTTP_LISTEN", want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			if got := closest(tt.key, known); got != tt.want {
+				t.Errorf("closest(%q) = %q, want %q", tt.key, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,35 @@
+package env
+
+import (
+	"fmt"
+	"log/slog"
+)
+
+// Secret — строка, которая не попадает в логи и вывод fmt: String, GoString, MarshalText
+// и LogValue возвращают [REDACTED]. Исходное значение возвращает Value.
+// Поля типа Secret считаются секретными без опции secret.
+type Secret string
+
+// Value возвращает исходное значение секрета.
+func (s Secret) Value() string {
+	return string(s)
+}
+
+func (s Secret) String() string {
+	if s == "" {
+		return ""
+	}
+	return Redacted
+}
+
+func (s Secret) GoString() string {
+	return fmt.Sprintf("env.Secret(%q)", s.String())
+}
+
+func (s Secret) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+func (s Secret) LogValue() slog.Value {
+	return slog.StringValue(s.String())
+}
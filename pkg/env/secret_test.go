@@ -0,0 +1,200 @@
+package env
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// dotenv записывает строки во временный .env файл и возвращает путь к нему.
+func dotenv(t *testing.T, lines ...string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), ".env")
+	if err := os.WriteFile(path, []byte(strings.Join(lines, "\n")), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestSecretFormatting(t *testing.T) {
+	tests := []struct {
+		name   string
+		secret Secret
+		want   string
+	}{
+		{name: "set", secret: "hunter2", want: Redacted},
+		{name: "empty", secret: "", want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			text, _ := tt.secret.MarshalText()
+			got := map[string]string{
+				"String":      tt.secret.String(),
+				"Sprint":      fmt.Sprint(tt.secret),
+				"Sprintf %v":  fmt.Sprintf("%v", tt.secret),
+				"MarshalText": string(text),
+				"LogValue":    tt.secret.LogValue().String(),
+			}
+			for method, value := range got {
+				if value != tt.want {
+					t.Errorf("%s = %q, want %q", method, value, tt.want)
+				}
+			}
+			if tt.secret.LogValue().Kind() != slog.KindString {
+				t.Errorf("LogValue kind = %s, want string", tt.secret.LogValue().Kind())
+			}
+			if got := tt.secret.Value(); got != string(tt.secret) {
+				t.Errorf("Value = %q, want %q", got, tt.secret)
+			}
+			if strings.Contains(fmt.Sprintf("%#v", tt.secret), "hunter2") {
+				t.Errorf("GoString leaks the value: %#v", tt.secret)
+			}
+		})
+	}
+}
+
+func TestSecretRules(t *testing.T) {
+	type config struct {
+		Token Secret `env:"TOKEN,min=8,oneof=correct-horse wrong-horse-battery"`
+	}
+
+	tests := []struct {
+		name    string
+		value   string
+		wantErr string
+	}{
+		{name: "valid", value: "correct-horse"},
+		{name: "not in oneof", value: "incorrect-horse", wantErr: "[REDACTED] must be one of"},
+		{name: "too short", value: "short", wantErr: "must be at least 8 characters"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var cfg config
+			err := LoadFile(&cfg, dotenv(t, "TOKEN="+tt.value))
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				if cfg.Token.Value() != tt.value {
+					t.Errorf("Token = %q, want %q", cfg.Token.Value(), tt.value)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("error = %v, want %q", err, tt.wantErr)
+			}
+			if strings.Contains(err.Error(), tt.value) {
+				t.Errorf("error leaks the secret: %v", err)
+			}
+		})
+	}
+}
+
+func TestFileValues(t *testing.T) {
+	type config struct {
+		Password Secret `env:"PASSWORD"`
+		CA       string `env:"CA,file"`
+		Pin      int    `env:"PIN,secret"`
+	}
+
+	dir := t.TempDir()
+	secretFile := filepath.Join(dir, "password")
+	if err := os.WriteFile(secretFile, []byte("  from-file\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	caFile := filepath.Join(dir, "ca.pem")
+	if err := os.WriteFile(caFile, []byte("certificate\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name         string
+		lines        []string
+		env          map[string]string
+		wantPassword string
+		wantCA       string
+		wantErr      string
+	}{
+		{
+			name:         "value from KEY",
+			lines:        []string{"PASSWORD=inline"},
+			wantPassword: "inline",
+		},
+		{
+			name:         "value from KEY_FILE is trimmed",
+			lines:        []string{"PASSWORD_FILE=" + secretFile},
+			wantPassword: "from-file",
+		},
+		{
+			name:    "KEY and KEY_FILE together",
+			lines:   []string{"PASSWORD=inline", "PASSWORD_FILE=" + secretFile},
+			wantErr: "both PASSWORD and PASSWORD_FILE are set",
+		},
+		{
+			name:    "KEY and KEY_FILE together in the environment",
+			env:     map[string]string{"PASSWORD": "inline", "PASSWORD_FILE": secretFile},
+			wantErr: "both PASSWORD and PASSWORD_FILE are set",
+		},
+		{
+			name:         "KEY_FILE from the environment overrides KEY from .env",
+			lines:        []string{"PASSWORD=inline"},
+			env:          map[string]string{"PASSWORD_FILE": secretFile},
+			wantPassword: "from-file",
+		},
+		{
+			name:         "KEY from the environment overrides KEY_FILE from .env",
+			lines:        []string{"PASSWORD_FILE=" + secretFile},
+			env:          map[string]string{"PASSWORD": "from-env"},
+			wantPassword: "from-env",
+		},
+		{
+			name:    "missing KEY_FILE",
+			lines:   []string{"PASSWORD_FILE=" + filepath.Join(dir, "missing")},
+			wantErr: "field PASSWORD: read file",
+		},
+		{
+			name:   "file option reads KEY as a path",
+			lines:  []string{"CA=" + caFile},
+			wantCA: "certificate",
+		},
+		{
+			name:    "parse error hides the secret",
+			lines:   []string{"PIN=12x45"},
+			wantErr: "field PIN: invalid int [REDACTED]: invalid syntax",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for key, value := range tt.env {
+				t.Setenv(key, value)
+			}
+
+			var cfg config
+			err := Load(&cfg, dotenv(t, tt.lines...))
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("error = %v, want %q", err, tt.wantErr)
+				}
+				if strings.Contains(err.Error(), "12x45") {
+					t.Errorf("error leaks the secret: %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if cfg.Password.Value() != tt.wantPassword {
+				t.Errorf("Password = %q, want %q", cfg.Password.Value(), tt.wantPassword)
+			}
+			if cfg.CA != tt.wantCA {
+				t.Errorf("CA = %q, want %q", cfg.CA, tt.wantCA)
+			}
+		})
+	}
+}
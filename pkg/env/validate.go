@@ -0,0 +1,199 @@
+package env
+
+import (
+	"cmp"
+	"fmt"
+	"net"
+	"net/url"
+	"reflect"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Validator — необязательный интерфейс структуры конфигурации. Validate вызывается после
+// загрузки всех полей для проверок, которые не выражаются тегами, например, MIN_CONNS <= MAX_CONNS.
+type Validator interface {
+	Validate() error
+}
+
+// rules — ограничения значения из тега env:
+//
+//	min=N, max=N  число, длительность, длина строки или число элементов среза
+//	oneof=a b c   одно из значений через пробел
+//	url           абсолютный URL со схемой и хостом
+//	hostport      host:port
+//	regex=EXPR    совпадение с регулярным выражением; должно быть последней опцией тега
+//
+// Для срезов oneof, url, hostport и regex проверяются для каждого элемента, для map —
+// для каждого значения.
+type rules struct {
+	min      string
+	max      string
+	oneof    []string
+	url      bool
+	hostport bool
+	regex    *regexp.Regexp
+}
+
+// check проверяет загруженное значение поля и возвращает все нарушения.
+// Значения секретных полей в ошибки не попадают.
+func (r rules) check(v reflect.Value, secret bool) []error {
+	if v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+
+	var errs []error
+	if r.min != "" {
+		if c, err := compare(v, r.min); err != nil {
+			errs = append(errs, fmt.Errorf("invalid min: %w", err))
+		} else if c < 0 {
+			errs = append(errs, fmt.Errorf("must be at least %s%s", r.min, unit(v)))
+		}
+	}
+	if r.max != "" {
+		if c, err := compare(v, r.max); err != nil {
+			errs = append(errs, fmt.Errorf("invalid max: %w", err))
+		} else if c > 0 {
+			errs = append(errs, fmt.Errorf("must be at most %s%s", r.max, unit(v)))
+		}
+	}
+
+	items, keys := []reflect.Value{v}, []string{""}
+	switch {
+	case decodable(v.Type()):
+	case v.Kind() == reflect.Slice:
+		items, keys = items[:0], keys[:0]
+		for i := range v.Len() {
+			items, keys = append(items, v.Index(i)), append(keys, "")
+		}
+	case v.Kind() == reflect.Map:
+		items, keys = items[:0], keys[:0]
+		mapKeys := v.MapKeys()
+		slices.SortFunc(mapKeys, func(a, b reflect.Value) int { return strings.Compare(text(a), text(b)) })
+		for _, k := range mapKeys {
+			items, keys = append(items, v.MapIndex(k)), append(keys, text(k))
+		}
+	}
+	for i, item := range items {
+		s := text(item)
+		if item.Kind() == reflect.String {
+			// Строки проверяются по исходному значению: text вернул бы результат
+			// MarshalText, а у Secret это [REDACTED].
+			s = item.String()
+		}
+		shown := strconv.Quote(s)
+		if keys[i] != "" {
+			shown = fmt.Sprintf("value %s of key %q", shown, keys[i])
+		}
+		if secret {
+			shown = Redacted
+		}
+		if len(r.oneof) > 0 && !slices.Contains(r.oneof, s) {
+			errs = append(errs, fmt.Errorf("%s must be one of: %s", shown, strings.Join(r.oneof, ", ")))
+		}
+		if r.url {
+			if u, err := url.Parse(s); err != nil || u.Scheme == "" || u.Host == "" {
+				errs = append(errs, fmt.Errorf("%s must be an absolute URL", shown))
+			}
+		}
+		if r.hostport {
+			if _, port, err := net.SplitHostPort(s); err != nil || !validPort(port) {
+				errs = append(errs, fmt.Errorf("%s must be host:port", shown))
+			}
+		}
+		if r.regex != nil && !r.regex.MatchString(s) {
+			errs = append(errs, fmt.Errorf("%s must match %s", shown, r.regex))
+		}
+	}
+	return errs
+}
+
+// compare сравнивает значение с границей из тега: для строк и срезов сравнивается длина.
+func compare(v reflect.Value, limit string) (int, error) {
+	if v.Type() == reflect.TypeFor[time.Duration]() {
+		d, err := time.ParseDuration(limit)
+		if err != nil {
+			return 0, err
+		}
+		return cmp.Compare(time.Duration(v.Int()), d), nil
+	}
+
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(limit, 10, 64)
+		if err != nil {
+			return 0, err
+		}
+		return cmp.Compare(v.Int(), n), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(limit, 10, 64)
+		if err != nil {
+			return 0, err
+		}
+		return cmp.Compare(v.Uint(), n), nil
+	case reflect.Float32, reflect.Float64:
+		n, err := strconv.ParseFloat(limit, 64)
+		if err != nil {
+			return 0, err
+		}
+		return cmp.Compare(v.Float(), n), nil
+	case reflect.String, reflect.Slice, reflect.Map:
+		n, err := strconv.Atoi(limit)
+		if err != nil {
+			return 0, err
+		}
+		return cmp.Compare(v.Len(), n), nil
+	default:
+		return 0, fmt.Errorf("unsupported type %s", v.Kind())
+	}
+}
+
+func unit(v reflect.Value) string {
+	switch v.Kind() {
+	case reflect.String:
+		return " characters"
+	case reflect.Slice, reflect.Map:
+		return " items"
+	default:
+		return ""
+	}
+}
+
+func validPort(port string) bool {
+	n, err := strconv.ParseUint(port, 10, 16)
+	return err == nil && n > 0
+}
+
+// validateStructs вызывает Validate у cfg и вложенных структур, которые реализуют Validator.
+func validateStructs(v reflect.Value) []error {
+	var errs []error
+	if v.CanAddr() {
+		if validator, ok := v.Addr().Interface().(Validator); ok {
+			if err := validator.Validate(); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+
+	t := v.Type()
+	for i := range t.NumField() {
+		f := t.Field(i)
+		if !f.IsExported() || f.Tag.Get("env") == "-" {
+			continue
+		}
+		fv := v.Field(i)
+		if fv.Kind() == reflect.Pointer && !fv.IsNil() && fv.Elem().Kind() == reflect.Struct {
+			fv = fv.Elem()
+		}
+		if fv.Kind() == reflect.Struct && !decodable(fv.Type()) {
+			errs = append(errs, validateStructs(fv)...)
+		}
+	}
+	return errs
+}
@@ -0,0 +1,174 @@
+package env
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRules(t *testing.T) {
+	type config struct {
+		Port    int               `env:"PORT,min=1,max=65535"`
+		Timeout time.Duration     `env:"TIMEOUT,min=1s,max=1m"`
+		Name    string            `env:"NAME,min=2,max=5"`
+		Mode    string            `env:"MODE,oneof=fast slow"`
+		Modes   []string          `env:"MODES,oneof=fast slow"`
+		Hosts   []string          `env:"HOSTS,max=2,hostport"`
+		URL     string            `env:"URL,url"`
+		Token   string            `env:"TOKEN,secret,oneof=a b"`
+		ID      string            `env:"ID,regex=^[a-z]{2,3}(,[0-9])?$"`
+		Weights map[string]int    `env:"WEIGHTS,oneof=1 2 3"`
+		Routes  map[string]string `env:"ROUTES,kvsep==,url"`
+	}
+
+	tests := []struct {
+		name    string
+		lines   []string
+		wantErr []string
+	}{
+		{
+			name: "valid",
+			lines: []string{
+				"PORT=8080", "TIMEOUT=30s", "NAME=app", "MODE=fast", "MODES=fast,slow",
+				"HOSTS=db:5432,[::1]:5433", "URL=https://example.com/hook", "TOKEN=a", "ID=ab,1",
+				"WEIGHTS=a:1,b:3", "ROUTES=orders=https://example.com/orders",
+			},
+		},
+		{
+			name:    "number below min",
+			lines:   []string{"PORT=0"},
+			wantErr: []string{"field PORT: must be at least 1"},
+		},
+		{
+			name:    "number above max",
+			lines:   []string{"PORT=70000"},
+			wantErr: []string{"field PORT: must be at most 65535"},
+		},
+		{
+			name:    "duration bounds",
+			lines:   []string{"TIMEOUT=2m"},
+			wantErr: []string{"field TIMEOUT: must be at most 1m"},
+		},
+		{
+			name:    "string length",
+			lines:   []string{"NAME=a"},
+			wantErr: []string{"field NAME: must be at least 2 characters"},
+		},
+		{
+			name:    "oneof",
+			lines:   []string{"MODE=medium"},
+			wantErr: []string{`field MODE: "medium" must be one of: fast, slow`},
+		},
+		{
+			name:    "oneof checks every element",
+			lines:   []string{"MODES=fast,medium"},
+			wantErr: []string{`field MODES: "medium" must be one of`},
+		},
+		{
+			name:  "slice length and hostport",
+			lines: []string{"HOSTS=a:1,b:2,c"},
+			wantErr: []string{
+				"field HOSTS: must be at most 2 items",
+				`field HOSTS: "c" must be host:port`,
+			},
+		},
+		{
+			name:    "invalid port in hostport",
+			lines:   []string{"HOSTS=db:0"},
+			wantErr: []string{`"db:0" must be host:port`},
+		},
+		{
+			name:    "relative url",
+			lines:   []string{"URL=/hook"},
+			wantErr: []string{`field URL: "/hook" must be an absolute URL`},
+		},
+		{
+			name:    "secret value is not shown",
+			lines:   []string{"TOKEN=c"},
+			wantErr: []string{"field TOKEN: [REDACTED] must be one of: a, b"},
+		},
+		{
+			name:  "oneof checks every map value",
+			lines: []string{"WEIGHTS=a:1,b:5,c:2,d:4"},
+			wantErr: []string{
+				`field WEIGHTS: value "5" of key "b" must be one of: 1, 2, 3`,
+				`field WEIGHTS: value "4" of key "d" must be one of: 1, 2, 3`,
+			},
+		},
+		{
+			name:    "url checks every map value",
+			lines:   []string{"ROUTES=orders=https://example.com/orders,users=/users"},
+			wantErr: []string{`field ROUTES: value "/users" of key "users" must be an absolute URL`},
+		},
+		{
+			name:    "regex with a comma",
+			lines:   []string{"ID=abcd"},
+			wantErr: []string{`field ID: "abcd" must match`},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var cfg config
+			err := LoadFile(&cfg, dotenv(t, tt.lines...))
+			if len(tt.wantErr) == 0 {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatalf("expected errors %q, got nil", tt.wantErr)
+			}
+			for _, want := range tt.wantErr {
+				if !strings.Contains(err.Error(), want) {
+					t.Errorf("error %q does not contain %q", err, want)
+				}
+			}
+		})
+	}
+}
+
+type limits struct {
+	Min int `env:"MIN,default=1"`
+	Max int `env:"MAX,default=10"`
+}
+
+func (l *limits) Validate() error {
+	if l.Min > l.Max {
+		return errors.New("MIN cannot exceed MAX")
+	}
+	return nil
+}
+
+func TestValidator(t *testing.T) {
+	type config struct {
+		Limits limits `env:"LIMITS"`
+	}
+
+	tests := []struct {
+		name    string
+		lines   []string
+		wantErr string
+	}{
+		{name: "valid", lines: []string{"LIMITS_MIN=2"}},
+		{name: "nested Validate", lines: []string{"LIMITS_MIN=20"}, wantErr: "MIN cannot exceed MAX"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var cfg config
+			err := LoadFile(&cfg, dotenv(t, tt.lines...))
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
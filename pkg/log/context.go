@@ -0,0 +1,16 @@
+package log
+
+import "context"
+
+type requestIDKey struct{}
+
+// ContextWithRequestID возвращает копию контекста с идентификатором запроса.
+func ContextWithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+// RequestID возвращает идентификатор запроса из контекста или пустую строку.
+func RequestID(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
@@ -18,14 +18,14 @@ type Handler interface {
 	// Start запускает обработчик. Блокируется до тех пор, пока не произойдёт
 	// ошибка или не будет отменён контекст.
 	Start(context.Context) error
-	// Stop останавливает обработчик. Вызывается после завершения Start.
+	// Stop останавливает обработчик. Вызывается после отмены контекста Start.
 	// Контекст в Stop может быть отдельным (например, с таймаутом).
 	Stop(context.Context) error
 }
 
-// Runner управляет жизненным циклом Handler с обработкой сигналов ОС.
+// Runner управляет жизненным циклом одного или нескольких Handler с обработкой сигналов ОС.
 type Runner struct {
-	handler     Handler
+	handlers    []Handler
 	signals     []os.Signal
 	stopTimeout time.Duration // опциональный таймаут для Stop
 	once        atomic.Bool   // защита от повторного запуска
@@ -34,13 +34,16 @@ type Runner struct {
 // Option настраивает Runner.
 type Option func(*Runner) error
 
-// WithHandler устанавливает обрабатываемый компонент (обязательно).
+// WithHandler добавляет обрабатываемый компонент (требуется хотя бы один).
+// Компоненты запускаются одновременно и останавливаются в обратном порядке добавления:
+// контекст Start отменяется перед Stop того же компонента, поэтому компоненты, добавленные
+// раньше, работают, пока не остановлены добавленные после них.
 func WithHandler(handler Handler) Option {
 	return func(r *Runner) error {
 		if handler == nil {
 			return errors.New("runner: handler cannot be nil")
 		}
-		r.handler = handler
+		r.handlers = append(r.handlers, handler)
 		return nil
 	}
 }
@@ -73,15 +76,16 @@ func New(opts ...Option) (*Runner, error) {
 			return nil, err
 		}
 	}
-	if r.handler == nil {
+	if len(r.handlers) == 0 {
 		return nil, errors.New("runner: handler is required")
 	}
 	return r, nil
 }
 
-// Run запускает обработчик и ожидает его завершения.
+// Run запускает обработчики и ожидает их завершения.
 // Возвращает первую ошибку, возникшую в Start или Stop, либо nil при штатном
-// завершении по сигналу. Метод не должен вызываться повторно.
+// завершении по сигналу. Ошибка Start любого обработчика останавливает все остальные.
+// Метод не должен вызываться повторно.
 func (r *Runner) Run(ctx context.Context) error {
 	if !r.once.CompareAndSwap(false, true) {
 		return errors.New("runner: run already called")
@@ -92,23 +96,26 @@ func (r *Runner) Run(ctx context.Context) error {
 
 	g, ctx := errgroup.WithContext(ctx)
 
-	g.Go(func() (err error) {
-		defer func() {
-			if p := recover(); p != nil {
-				err = fmt.Errorf("runner: panic in Start: %v", p)
-				cancel()
-			}
-		}()
-		return r.handler.Start(ctx)
-	})
+	// Контексты обработчиков не отменяются вместе с ctx: при остановке они отменяются
+	// по одному, в том же порядке, в котором вызывается Stop.
+	cancels := make([]context.CancelFunc, len(r.handlers))
+	for i, handler := range r.handlers {
+		handlerCtx, handlerCancel := context.WithCancel(context.WithoutCancel(ctx))
+		defer handlerCancel()
+		cancels[i] = handlerCancel
+
+		g.Go(func() (err error) {
+			defer func() {
+				if p := recover(); p != nil {
+					err = fmt.Errorf("runner: panic in Start: %v", p)
+					cancel()
+				}
+			}()
+			return handler.Start(handlerCtx)
+		})
+	}
 
-	g.Go(func() (err error) {
-		defer func() {
-			if p := recover(); p != nil {
-				err = fmt.Errorf("runner: panic in Stop: %v", p)
-				cancel()
-			}
-		}()
+	g.Go(func() error {
 		<-ctx.Done()
 
 		stopCtx := ctx
@@ -117,8 +124,25 @@ func (r *Runner) Run(ctx context.Context) error {
 			stopCtx, stopCancel = context.WithTimeout(context.Background(), r.stopTimeout)
 			defer stopCancel()
 		}
-		return r.handler.Stop(stopCtx)
+
+		var errs []error
+		for i := len(r.handlers) - 1; i >= 0; i-- {
+			cancels[i]()
+			if err := stop(stopCtx, r.handlers[i]); err != nil {
+				errs = append(errs, err)
+			}
+		}
+		return errors.Join(errs...)
 	})
 
 	return g.Wait()
 }
+
+func stop(ctx context.Context, handler Handler) (err error) {
+	defer func() {
+		if p := recover(); p != nil {
+			err = fmt.Errorf("runner: panic in Stop: %v", p)
+		}
+	}()
+	return handler.Stop(ctx)
+}
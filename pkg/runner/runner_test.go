@@ -0,0 +1,227 @@
+package runner
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// recorder собирает порядок вызовов Stop всех обработчиков теста.
+type recorder struct {
+	mu      sync.Mutex
+	stopped []string
+}
+
+func (r *recorder) stop(name string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.stopped = append(r.stopped, name)
+}
+
+type handler struct {
+	name     string
+	rec      *recorder
+	startErr error
+	stopErr  error
+	panics   bool
+}
+
+func (h *handler) Start(ctx context.Context) error {
+	if h.panics {
+		panic("boom")
+	}
+	if h.startErr != nil {
+		return h.startErr
+	}
+	<-ctx.Done()
+	return nil
+}
+
+func (h *handler) Stop(context.Context) error {
+	h.rec.stop(h.name)
+	return h.stopErr
+}
+
+func TestRun(t *testing.T) {
+	errStart := errors.New("listen: address already in use")
+	errStop := errors.New("flush failed")
+
+	tests := []struct {
+		name        string
+		handlers    func(rec *recorder) []*handler
+		cancel      bool
+		wantErr     []string
+		wantStopped []string
+	}{
+		{
+			name: "context cancellation stops handlers in reverse order",
+			handlers: func(rec *recorder) []*handler {
+				return []*handler{{name: "db", rec: rec}, {name: "queue", rec: rec}, {name: "app", rec: rec}}
+			},
+			cancel:      true,
+			wantStopped: []string{"app", "queue", "db"},
+		},
+		{
+			name: "start error stops every handler in reverse order",
+			handlers: func(rec *recorder) []*handler {
+				return []*handler{{name: "db", rec: rec}, {name: "app", rec: rec, startErr: errStart}, {name: "scheduler", rec: rec}}
+			},
+			wantErr:     []string{errStart.Error()},
+			wantStopped: []string{"scheduler", "app", "db"},
+		},
+		{
+			name: "panic in start is recovered",
+			handlers: func(rec *recorder) []*handler {
+				return []*handler{{name: "db", rec: rec}, {name: "app", rec: rec, panics: true}}
+			},
+			wantErr:     []string{"runner: panic in Start: boom"},
+			wantStopped: []string{"app", "db"},
+		},
+		{
+			name: "stop errors do not skip other handlers",
+			handlers: func(rec *recorder) []*handler {
+				return []*handler{{name: "db", rec: rec, stopErr: errStop}, {name: "app", rec: rec, stopErr: errStop}}
+			},
+			cancel:      true,
+			wantErr:     []string{errStop.Error()},
+			wantStopped: []string{"app", "db"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := &recorder{}
+			opts := []Option{WithStopTimeout(time.Second)}
+			for _, h := range tt.handlers(rec) {
+				opts = append(opts, WithHandler(h))
+			}
+			r, err := New(opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			if tt.cancel {
+				time.AfterFunc(10*time.Millisecond, cancel)
+			}
+
+			err = r.Run(ctx)
+			if len(tt.wantErr) == 0 && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+			for _, want := range tt.wantErr {
+				if err == nil || !strings.Contains(err.Error(), want) {
+					t.Errorf("error = %v, want %q", err, want)
+				}
+			}
+			if !slices.Equal(rec.stopped, tt.wantStopped) {
+				t.Errorf("stopped = %v, want %v", rec.stopped, tt.wantStopped)
+			}
+		})
+	}
+}
+
+func TestRunTwice(t *testing.T) {
+	r, err := New(WithHandler(&handler{name: "app", rec: &recorder{}}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := r.Run(ctx); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := r.Run(ctx); err == nil || err.Error() != "runner: run already called" {
+		t.Errorf("second Run error = %v, want run already called", err)
+	}
+}
+
+func TestNew(t *testing.T) {
+	tests := []struct {
+		name    string
+		opts    []Option
+		wantErr string
+	}{
+		{name: "no handlers", wantErr: "runner: handler is required"},
+		{name: "nil handler", opts: []Option{WithHandler(nil)}, wantErr: "runner: handler cannot be nil"},
+		{name: "valid", opts: []Option{WithHandler(&handler{})}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := New(tt.opts...)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || err.Error() != tt.wantErr {
+				t.Errorf("error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// ctxHandler запоминает контекст Start, а в Stop записывает, чьи контексты ещё не отменены.
+type ctxHandler struct {
+	name    string
+	rec     *recorder
+	all     []*ctxHandler
+	started chan struct{}
+	ctx     context.Context
+}
+
+func (h *ctxHandler) Start(ctx context.Context) error {
+	h.ctx = ctx
+	close(h.started)
+	<-ctx.Done()
+	return nil
+}
+
+func (h *ctxHandler) Stop(context.Context) error {
+	var alive []string
+	for _, other := range h.all {
+		<-other.started
+		if other.ctx.Err() == nil {
+			alive = append(alive, other.name)
+		}
+	}
+	h.rec.stop(h.name + ": " + strings.Join(alive, ","))
+	return nil
+}
+
+func TestRunCancelsHandlersInReverseOrder(t *testing.T) {
+	rec := &recorder{}
+	var handlers []*ctxHandler
+	for _, name := range []string{"db", "queue", "app"} {
+		handlers = append(handlers, &ctxHandler{name: name, rec: rec, started: make(chan struct{})})
+	}
+
+	opts := []Option{WithStopTimeout(time.Second)}
+	for _, h := range handlers {
+		h.all = handlers
+		opts = append(opts, WithHandler(h))
+	}
+	r, err := New(opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	time.AfterFunc(10*time.Millisecond, cancel)
+
+	if err := r.Run(ctx); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := []string{"app: db,queue", "queue: db", "db: "}; !slices.Equal(rec.stopped, want) {
+		t.Errorf("running handlers at each Stop = %q, want %q", rec.stopped, want)
+	}
+}
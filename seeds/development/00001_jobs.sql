@@ -0,0 +1,9 @@
+-- +goose Up
+-- Примеры задач во всех состояниях для проверки административного API.
+INSERT INTO jobs (queue, kind, payload, status, attempts, max_attempts, last_error, finished_at) VALUES
+    ('default', 'example.noop', '{"n": 1}', 'completed', 1, 25, NULL, now()),
+    ('default', 'example.noop', '{"n": 2}', 'dead', 3, 3, 'example failure', now()),
+    ('default', 'example.noop', '{"n": 3}', 'cancelled', 0, 25, NULL, now());
+
+INSERT INTO jobs (queue, kind, payload, run_at) VALUES
+    ('default', 'example.noop', '{"n": 4}', now() + interval '1 day');
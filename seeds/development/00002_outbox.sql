@@ -0,0 +1,4 @@
+-- +goose Up
+INSERT INTO outbox (topic, key, payload, attempts, delivered_at) VALUES
+    ('example.created', 'example-1', '{"id": 1}', 1, now()),
+    ('example.updated', 'example-1', '{"id": 1, "name": "updated"}', 1, now());
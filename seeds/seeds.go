@@ -0,0 +1,29 @@
+// Package seeds содержит наборы тестовых данных для окружений development и test.
+// SQL-сиды лежат в каталоге набора, Go-сиды регистрируются через register.
+package seeds
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"slices"
+
+	"github.com/pressly/goose/v3"
+)
+
+//go:embed development/*.sql test/*.sql
+var FS embed.FS
+
+type seedFunc func(ctx context.Context, tx *sql.Tx) error
+
+var goSeeds = make(map[string][]*goose.Migration)
+
+// register добавляет Go-сид в набор set. Вызывается из init() в файлах этого пакета.
+func register(set string, version int64, fn seedFunc) {
+	goSeeds[set] = append(goSeeds[set], goose.NewGoMigration(version, &goose.GoFunc{RunTx: fn}, nil))
+}
+
+// GoSeeds возвращает Go-сиды набора set.
+func GoSeeds(set string) []*goose.Migration {
+	return slices.Clone(goSeeds[set])
+}
@@ -0,0 +1,7 @@
+-- +goose Up
+-- Детерминированные данные для интеграционных тестов.
+INSERT INTO jobs (queue, kind, payload, status, unique_key, run_at, created_at, updated_at) VALUES
+    ('default', 'test.pending', '{}', 'pending', 'test-pending', '2026-01-01T00:00:00Z', '2026-01-01T00:00:00Z', '2026-01-01T00:00:00Z');
+
+INSERT INTO jobs (queue, kind, payload, status, attempts, max_attempts, last_error, run_at, created_at, updated_at, finished_at) VALUES
+    ('default', 'test.dead', '{}', 'dead', 1, 1, 'test failure', '2026-01-01T00:00:00Z', '2026-01-01T00:00:00Z', '2026-01-01T00:00:00Z', '2026-01-01T00:00:00Z');